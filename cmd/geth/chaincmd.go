@@ -42,6 +42,10 @@ import (
 )
 
 var (
+	receiptsFlag = cli.BoolFlag{
+		Name:  "receipts",
+		Usage: "Export/import blocks along with their receipts and total difficulty, importing them without execution",
+	}
 	initCommand = cli.Command{
 		Action:    utils.MigrateFlags(initGenesis),
 		Name:      "init",
@@ -98,6 +102,7 @@ The dumpgenesis command dumps the genesis block configuration in JSON format to
 			utils.MetricsInfluxDBPasswordFlag,
 			utils.MetricsInfluxDBTagsFlag,
 			utils.TxLookupLimitFlag,
+			receiptsFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -105,7 +110,11 @@ The import command imports blocks from an RLP-encoded form. The form can be one
 with several RLP-encoded blocks, or several files can be used.
 
 If only one file is used, import error will result in failure. If several files are used,
-processing will proceed even if an individual RLP-file import failure occurs.`,
+processing will proceed even if an individual RLP-file import failure occurs.
+
+With --receipts, the files are expected to be exported with --receipts too. The blocks
+are then inserted along with their receipts without executing them, similarly to fast
+sync, so the state of the imported chain still needs to be synced afterwards.`,
 	}
 	exportCommand = cli.Command{
 		Action:    utils.MigrateFlags(exportChain),
@@ -116,6 +125,7 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 			utils.DataDirFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
+			receiptsFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -123,7 +133,10 @@ Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
-be gzipped.`,
+be gzipped.
+
+With --receipts, every block is exported along with its receipts
+and total difficulty, up to the fast sync head by default.`,
 	}
 	importPreimagesCommand = cli.Command{
 		Action:    utils.MigrateFlags(importPreimages),
@@ -259,14 +272,18 @@ func importChain(ctx *cli.Context) error {
 
 	var importErr error
 
+	importFn := utils.ImportChain
+	if ctx.Bool(receiptsFlag.Name) {
+		importFn = utils.ImportReceiptChain
+	}
 	if len(ctx.Args()) == 1 {
-		if err := utils.ImportChain(chain, ctx.Args().First()); err != nil {
+		if err := importFn(chain, ctx.Args().First()); err != nil {
 			importErr = err
 			log.Error("Import error", "err", err)
 		}
 	} else {
 		for _, arg := range ctx.Args() {
-			if err := utils.ImportChain(chain, arg); err != nil {
+			if err := importFn(chain, arg); err != nil {
 				importErr = err
 				log.Error("Import error", "file", arg, "err", err)
 			}
@@ -317,7 +334,11 @@ func exportChain(ctx *cli.Context) error {
 	var err error
 	fp := ctx.Args().First()
 	if len(ctx.Args()) < 3 {
-		err = utils.ExportChain(chain, fp)
+		if ctx.Bool(receiptsFlag.Name) {
+			err = utils.ExportReceiptChain(chain, fp, 0, chain.CurrentFastBlock().NumberU64(), false)
+		} else {
+			err = utils.ExportChain(chain, fp)
+		}
 	} else {
 		// This can be improved to allow for numbers larger than 9223372036854775807
 		first, ferr := strconv.ParseInt(ctx.Args().Get(1), 10, 64)
@@ -331,7 +352,11 @@ func exportChain(ctx *cli.Context) error {
 		if head := chain.CurrentFastBlock(); uint64(last) > head.NumberU64() {
 			utils.Fatalf("Export error: block number %d larger than head block %d\n", uint64(last), head.NumberU64())
 		}
-		err = utils.ExportAppendChain(chain, fp, uint64(first), uint64(last))
+		if ctx.Bool(receiptsFlag.Name) {
+			err = utils.ExportReceiptChain(chain, fp, uint64(first), uint64(last), true)
+		} else {
+			err = utils.ExportAppendChain(chain, fp, uint64(first), uint64(last))
+		}
 	}
 
 	if err != nil {
@@ -28,6 +28,7 @@ import (
 	"gopkg.in/urfave/cli.v1"
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/eth/catalyst"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
@@ -144,12 +145,39 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	return stack, cfg
 }
 
+// makeChainOverrides merges the fork block overrides set on the command line
+// into the ones of the configuration, returning nil if none were specified.
+func makeChainOverrides(ctx *cli.Context, cfg *ethconfig.Config) *core.ChainOverrides {
+	var overrides core.ChainOverrides
+	if cfg.Overrides != nil {
+		overrides = *cfg.Overrides
+	}
+	for _, override := range []struct {
+		flag  cli.Uint64Flag
+		block **big.Int
+	}{
+		{utils.OverrideByzantiumFlag, &overrides.Byzantium},
+		{utils.OverrideConstantinopleFlag, &overrides.Constantinople},
+		{utils.OverridePetersburgFlag, &overrides.Petersburg},
+		{utils.OverrideIstanbulFlag, &overrides.Istanbul},
+		{utils.OverrideMuirGlacierFlag, &overrides.MuirGlacier},
+		{utils.OverrideBerlinFlag, &overrides.Berlin},
+		{utils.OverrideLondonFlag, &overrides.London},
+	} {
+		if ctx.GlobalIsSet(override.flag.Name) {
+			*override.block = new(big.Int).SetUint64(ctx.GlobalUint64(override.flag.Name))
+		}
+	}
+	if overrides == (core.ChainOverrides{}) {
+		return nil
+	}
+	return &overrides
+}
+
 // makeFullNode loads geth configuration and creates the Ethereum backend.
 func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	stack, cfg := makeConfigNode(ctx)
-	if ctx.GlobalIsSet(utils.OverrideLondonFlag.Name) {
-		cfg.Eth.OverrideLondon = new(big.Int).SetUint64(ctx.GlobalUint64(utils.OverrideLondonFlag.Name))
-	}
+	cfg.Eth.Overrides = makeChainOverrides(ctx, &cfg.Eth)
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
 
 	// Configure catalyst.
@@ -0,0 +1,79 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Tests that configs setting the deprecated OverrideLondon field still load,
+// survive a dump and are mapped into the fork block overrides.
+func TestDeprecatedOverrideLondon(t *testing.T) {
+	const config = `
+[Eth]
+OverrideLondon = 100
+
+[Eth.Overrides]
+Berlin = 50
+`
+	cfg := gethConfig{Eth: ethconfig.Defaults, Node: defaultNodeConfig()}
+	if err := tomlSettings.NewDecoder(strings.NewReader(config)).Decode(&cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	out, err := tomlSettings.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("failed to dump config: %v", err)
+	}
+	dumped := gethConfig{Eth: ethconfig.Defaults, Node: defaultNodeConfig()}
+	if err := tomlSettings.NewDecoder(strings.NewReader(string(out))).Decode(&dumped); err != nil {
+		t.Fatalf("failed to reload dumped config: %v", err)
+	}
+	if dumped.Eth.OverrideLondon == nil || dumped.Eth.OverrideLondon.Uint64() != 100 {
+		t.Fatalf("deprecated London override mismatch: have %v, want %d", dumped.Eth.OverrideLondon, 100)
+	}
+	// Map the overrides without any command line flags
+	ctx := cli.NewContext(nil, flag.NewFlagSet("test", flag.ContinueOnError), nil)
+
+	dumped.Eth.Overrides = makeChainOverrides(ctx, &dumped.Eth)
+	overrides := dumped.Eth.ChainOverrides()
+	if overrides == nil {
+		t.Fatalf("missing chain overrides")
+	}
+	if overrides.London == nil || overrides.London.Uint64() != 100 {
+		t.Errorf("London override mismatch: have %v, want %d", overrides.London, 100)
+	}
+	if overrides.Berlin == nil || overrides.Berlin.Uint64() != 50 {
+		t.Errorf("Berlin override mismatch: have %v, want %d", overrides.Berlin, 50)
+	}
+	// The command line flag takes precedence over the deprecated field
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	utils.OverrideLondonFlag.Apply(set)
+	if err := set.Parse([]string{"--" + utils.OverrideLondonFlag.Name, "200"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	dumped.Eth.Overrides = makeChainOverrides(cli.NewContext(nil, set, nil), &dumped.Eth)
+	overrides = dumped.Eth.ChainOverrides()
+	if overrides.London == nil || overrides.London.Uint64() != 200 {
+		t.Errorf("flag London override mismatch: have %v, want %d", overrides.London, 200)
+	}
+}
@@ -62,6 +62,7 @@ Remove blockchain and state databases`,
 			dbPutCmd,
 			dbGetSlotsCmd,
 			dbDumpFreezerIndex,
+			dbPurgePreimagesCmd,
 		},
 	}
 	dbInspectCmd = cli.Command{
@@ -179,6 +180,23 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		},
 		Description: "This command looks up the specified database key from the database.",
 	}
+	dbPurgePreimagesCmd = cli.Command{
+		Action: utils.MigrateFlags(dbPurgePreimages),
+		Name:   "purge-preimages",
+		Usage:  "Delete all the stored trie key preimages",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.RopstenFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			utils.CalaverasFlag,
+		},
+		Description: `This command deletes the preimages of the hashed trie keys from the
+database, which are only needed by debug APIs resolving hashed keys (e.g. state
+dumps). Run the node without --cache.preimages to avoid recording new ones.`,
+	}
 	dbDumpFreezerIndex = cli.Command{
 		Action:    utils.MigrateFlags(freezerInspect),
 		Name:      "freezer-index",
@@ -332,6 +350,24 @@ func dbCompact(ctx *cli.Context) error {
 	return nil
 }
 
+// dbPurgePreimages deletes all the trie key preimages from the database.
+func dbPurgePreimages(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	start := time.Now()
+	deleted, err := rawdb.DeletePreimages(db)
+	if err != nil {
+		log.Error("Failed to purge preimages", "deleted", deleted, "error", err)
+		return err
+	}
+	log.Info("Purged trie key preimages", "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 // dbGet shows the value of a given database key
 func dbGet(ctx *cli.Context) error {
 	if ctx.NArg() != 1 {
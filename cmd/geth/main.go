@@ -66,6 +66,12 @@ var (
 		utils.NoUSBFlag,
 		utils.USBFlag,
 		utils.SmartCardDaemonPathFlag,
+		utils.OverrideByzantiumFlag,
+		utils.OverrideConstantinopleFlag,
+		utils.OverridePetersburgFlag,
+		utils.OverrideIstanbulFlag,
+		utils.OverrideMuirGlacierFlag,
+		utils.OverrideBerlinFlag,
 		utils.OverrideLondonFlag,
 		utils.EthashCacheDirFlag,
 		utils.EthashCachesInMemoryFlag,
@@ -79,6 +85,7 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolJournalLimitFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -86,21 +93,32 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolPendingLifetimeFlag,
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.AddressTxIndexFlag,
+		utils.BadBlockReportFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
 		utils.LightMaxPeersFlag,
+		utils.LightMaxPeersPerIPFlag,
+		utils.LightPriorityClientsFlag,
 		utils.LightNoPruneFlag,
 		utils.LightKDFFlag,
 		utils.UltraLightServersFlag,
 		utils.UltraLightFractionFlag,
 		utils.UltraLightOnlyAnnounceFlag,
 		utils.LightNoSyncServeFlag,
+		utils.LightSoftTimeoutFlag,
+		utils.LightHardTimeoutFlag,
+		utils.LightTxStatusRetriesFlag,
+		utils.LightTxStatusQuorumFlag,
+		utils.LightStateHistoryFlag,
+		utils.LightCheckpointFlag,
 		utils.WhitelistFlag,
 		utils.BloomFilterSizeFlag,
 		utils.CacheFlag,
@@ -140,6 +158,7 @@ var (
 		utils.GoerliFlag,
 		utils.CalaverasFlag,
 		utils.VMEnableDebugFlag,
+		utils.VMOpcodeMetricsFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
 		utils.FakePoWFlag,
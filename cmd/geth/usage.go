@@ -50,6 +50,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
 			utils.TxLookupLimitFlag,
+			utils.AddressTxIndexFlag,
+			utils.BadBlockReportFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
@@ -63,11 +65,19 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.LightIngressFlag,
 			utils.LightEgressFlag,
 			utils.LightMaxPeersFlag,
+			utils.LightMaxPeersPerIPFlag,
+			utils.LightPriorityClientsFlag,
 			utils.UltraLightServersFlag,
 			utils.UltraLightFractionFlag,
 			utils.UltraLightOnlyAnnounceFlag,
 			utils.LightNoPruneFlag,
 			utils.LightNoSyncServeFlag,
+			utils.LightSoftTimeoutFlag,
+			utils.LightHardTimeoutFlag,
+			utils.LightTxStatusRetriesFlag,
+			utils.LightTxStatusQuorumFlag,
+			utils.LightStateHistoryFlag,
+			utils.LightCheckpointFlag,
 		},
 	},
 	{
@@ -97,6 +107,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolNoLocalsFlag,
 			utils.TxPoolJournalFlag,
 			utils.TxPoolRejournalFlag,
+			utils.TxPoolJournalLimitFlag,
 			utils.TxPoolPriceLimitFlag,
 			utils.TxPoolPriceBumpFlag,
 			utils.TxPoolAccountSlotsFlag,
@@ -104,6 +115,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolPendingLifetimeFlag,
 		},
 	},
 	{
@@ -203,6 +215,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
 			utils.VMEnableDebugFlag,
+			utils.VMOpcodeMetricsFlag,
 		},
 	},
 	{
@@ -21,6 +21,7 @@ import (
 	"compress/gzip"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"os/signal"
 	"runtime"
@@ -39,11 +40,16 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
 	"gopkg.in/urfave/cli.v1"
 )
 
 const (
 	importBatchSize = 2500
+
+	// importHeaderCheckFreq is the frequency of header seal verifications while
+	// importing chain exports including receipts.
+	importHeaderCheckFreq = 100
 )
 
 // Fatalf formats a message to standard error and exits the program.
@@ -269,6 +275,149 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 	return nil
 }
 
+// ImportReceiptChain imports a chain export including receipts and total
+// difficulties, as produced by ExportReceiptChain. Similarly to fast sync, the
+// blocks are not executed, so no state is generated for them.
+func ImportReceiptChain(chain *core.BlockChain, fn string) error {
+	// Watch for Ctrl-C while the import is running.
+	// If a signal is received, the import will stop at the next batch.
+	interrupt := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	defer close(interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			log.Info("Interrupted during import, stopping at next batch")
+		}
+		close(stop)
+	}()
+	checkInterrupt := func() bool {
+		select {
+		case <-stop:
+			return true
+		default:
+			return false
+		}
+	}
+
+	log.Info("Importing blockchain with receipts", "file", fn)
+
+	// Open the file handle and potentially unwrap the gzip stream
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	stream := rlp.NewStream(reader, 0)
+
+	// Run actual the import.
+	n := 0
+	for {
+		// Load a batch of RLP blocks, validating them against their headers
+		if checkInterrupt() {
+			return fmt.Errorf("interrupted")
+		}
+		var (
+			blocks   types.Blocks
+			receipts []types.Receipts
+			tds      []*big.Int
+		)
+		for len(blocks) < importBatchSize {
+			var entry core.ExportedBlock
+			if err := stream.Decode(&entry); err == io.EOF {
+				break
+			} else if err != nil {
+				return fmt.Errorf("at block %d: %v", n, err)
+			}
+			n++
+
+			// don't import first block
+			block := entry.Block
+			if block.NumberU64() == 0 {
+				continue
+			}
+			if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != block.TxHash() {
+				return fmt.Errorf("block %d: transaction root mismatch: have %x, want %x", block.NumberU64(), hash, block.TxHash())
+			}
+			if hash := types.CalcUncleHash(block.Uncles()); hash != block.UncleHash() {
+				return fmt.Errorf("block %d: uncle root mismatch: have %x, want %x", block.NumberU64(), hash, block.UncleHash())
+			}
+			blockReceipts := make(types.Receipts, len(entry.Receipts))
+			for i, receipt := range entry.Receipts {
+				blockReceipts[i] = (*types.Receipt)(receipt)
+			}
+			if err := blockReceipts.DeriveFields(chain.Config(), block.Hash(), block.NumberU64(), block.Transactions()); err != nil {
+				return fmt.Errorf("block %d: %v", block.NumberU64(), err)
+			}
+			if hash := types.DeriveSha(blockReceipts, trie.NewStackTrie(nil)); hash != block.ReceiptHash() {
+				return fmt.Errorf("block %d: receipt root mismatch: have %x, want %x", block.NumberU64(), hash, block.ReceiptHash())
+			}
+			blocks, receipts, tds = append(blocks, block), append(receipts, blockReceipts), append(tds, entry.TD)
+		}
+		if len(blocks) == 0 {
+			break
+		}
+		// Import the batch, headers first, then the bodies and receipts
+		if checkInterrupt() {
+			return fmt.Errorf("interrupted")
+		}
+		headers := make([]*types.Header, len(blocks))
+		for i, block := range blocks {
+			headers[i] = block.Header()
+		}
+		if _, err := chain.InsertHeaderChain(headers, importHeaderCheckFreq); err != nil {
+			return fmt.Errorf("invalid header chain: %v", err)
+		}
+		for i, block := range blocks {
+			if td := chain.GetTd(block.Hash(), block.NumberU64()); td == nil || td.Cmp(tds[i]) != 0 {
+				return fmt.Errorf("block %d: total difficulty mismatch: have %v, want %v", block.NumberU64(), td, tds[i])
+			}
+		}
+		if _, err := chain.InsertReceiptChain(blocks, receipts, 0); err != nil {
+			return fmt.Errorf("invalid receipt chain: %v", err)
+		}
+	}
+	return nil
+}
+
+// ExportReceiptChain exports a blockchain along with the receipts and total
+// difficulties into the specified file. If appendFile is set, the export is added
+// to any data already present in the file, otherwise the file is truncated.
+func ExportReceiptChain(blockchain *core.BlockChain, fn string, first uint64, last uint64, appendFile bool) error {
+	log.Info("Exporting blockchain with receipts", "file", fn)
+
+	// Open the file handle and potentially wrap with a gzip stream
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendFile {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	fh, err := os.OpenFile(fn, flags, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	// Iterate over the blocks and export them
+	if err := blockchain.ExportWithReceiptsN(writer, first, last); err != nil {
+		return err
+	}
+	log.Info("Exported blockchain with receipts", "file", fn)
+	return nil
+}
+
 // ImportPreimages imports a batch of exported hash preimages into the database.
 func ImportPreimages(db ethdb.Database, fn string) error {
 	log.Info("Importing preimages", "file", fn)
@@ -0,0 +1,100 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that a chain exported with receipts can be imported into a fresh node
+// without executing it.
+func TestExportImportReceiptChain(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{address: {Balance: big.NewInt(1000000000000000)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	// Generate and import a source chain containing some transactions
+	srcdb := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(srcdb)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), srcdb, 8, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), common.Address{0x01}, big.NewInt(1000), params.TxGas, gen.BaseFee(), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	src, _ := core.NewBlockChain(srcdb, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer src.Stop()
+	if _, err := src.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert source chain: %v", err)
+	}
+	// Export the source chain and import it into a fresh one
+	dir, err := ioutil.TempDir("", "receipt-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "chain.rlp.gz")
+	if err := ExportReceiptChain(src, fn, 0, src.CurrentBlock().NumberU64(), false); err != nil {
+		t.Fatalf("failed to export chain: %v", err)
+	}
+	dstdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(dstdb)
+	dst, _ := core.NewBlockChain(dstdb, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer dst.Stop()
+	if err := ImportReceiptChain(dst, fn); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	// Check that the destination mirrors the blocks, receipts and difficulties
+	if head := dst.CurrentFastBlock(); head.Hash() != src.CurrentBlock().Hash() {
+		t.Fatalf("fast head mismatch: have #%d [%x], want #%d [%x]", head.NumberU64(), head.Hash(), src.CurrentBlock().NumberU64(), src.CurrentBlock().Hash())
+	}
+	for _, block := range blocks {
+		if dst.GetTd(block.Hash(), block.NumberU64()).Cmp(src.GetTd(block.Hash(), block.NumberU64())) != 0 {
+			t.Errorf("block %d: total difficulty mismatch", block.NumberU64())
+		}
+		have, want := dst.GetReceiptsByHash(block.Hash()), src.GetReceiptsByHash(block.Hash())
+		if len(have) != len(want) {
+			t.Fatalf("block %d: receipt count mismatch: have %d, want %d", block.NumberU64(), len(have), len(want))
+		}
+		for i := range have {
+			if have[i].TxHash != want[i].TxHash || have[i].CumulativeGasUsed != want[i].CumulativeGasUsed || have[i].Status != want[i].Status {
+				t.Errorf("block %d: receipt %d mismatch", block.NumberU64(), i)
+			}
+		}
+	}
+	// Importing the same file again should be a noop
+	if err := ImportReceiptChain(dst, fn); err != nil {
+		t.Fatalf("failed to reimport chain: %v", err)
+	}
+}
@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -230,6 +231,14 @@ var (
 		Usage: "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value: ethconfig.Defaults.TxLookupLimit,
 	}
+	BadBlockReportFlag = cli.StringFlag{
+		Name:  "badblock.report",
+		Usage: "URL to POST a JSON report of every block failing validation to",
+	}
+	AddressTxIndexFlag = cli.BoolFlag{
+		Name:  "addresstxindex",
+		Usage: "Maintain an index of transactions by sender and recipient address, covering the same blocks as --txlookuplimit",
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -243,6 +252,30 @@ var (
 		Usage: "Megabytes of memory allocated to bloom-filter for pruning",
 		Value: 2048,
 	}
+	OverrideByzantiumFlag = cli.Uint64Flag{
+		Name:  "override.byzantium",
+		Usage: "Manually specify Byzantium fork-block, overriding the bundled setting",
+	}
+	OverrideConstantinopleFlag = cli.Uint64Flag{
+		Name:  "override.constantinople",
+		Usage: "Manually specify Constantinople fork-block, overriding the bundled setting",
+	}
+	OverridePetersburgFlag = cli.Uint64Flag{
+		Name:  "override.petersburg",
+		Usage: "Manually specify Petersburg fork-block, overriding the bundled setting",
+	}
+	OverrideIstanbulFlag = cli.Uint64Flag{
+		Name:  "override.istanbul",
+		Usage: "Manually specify Istanbul fork-block, overriding the bundled setting",
+	}
+	OverrideMuirGlacierFlag = cli.Uint64Flag{
+		Name:  "override.muirglacier",
+		Usage: "Manually specify Muir Glacier fork-block, overriding the bundled setting",
+	}
+	OverrideBerlinFlag = cli.Uint64Flag{
+		Name:  "override.berlin",
+		Usage: "Manually specify Berlin fork-block, overriding the bundled setting",
+	}
 	OverrideLondonFlag = cli.Uint64Flag{
 		Name:  "override.london",
 		Usage: "Manually specify London fork-block, overriding the bundled setting",
@@ -268,6 +301,15 @@ var (
 		Usage: "Maximum number of light clients to serve, or light servers to attach to",
 		Value: ethconfig.Defaults.LightPeers,
 	}
+	LightMaxPeersPerIPFlag = cli.IntFlag{
+		Name:  "light.maxpeersperip",
+		Usage: "Maximum number of light clients to serve from the same IP address (0 = unlimited)",
+		Value: ethconfig.Defaults.LightPeersPerIP,
+	}
+	LightPriorityClientsFlag = cli.StringFlag{
+		Name:  "light.priorityclients",
+		Usage: "Comma separated enode URLs or IDs of light clients always served with priority",
+	}
 	UltraLightServersFlag = cli.StringFlag{
 		Name:  "ulc.servers",
 		Usage: "List of trusted ultra-light servers",
@@ -290,6 +332,35 @@ var (
 		Name:  "light.nosyncserve",
 		Usage: "Enables serving light clients before syncing",
 	}
+	LightSoftTimeoutFlag = cli.DurationFlag{
+		Name:  "light.softtimeout",
+		Usage: "Time after which an unanswered light client request is resent to another server (0 = adaptive)",
+		Value: ethconfig.Defaults.LightSoftTimeout,
+	}
+	LightHardTimeoutFlag = cli.DurationFlag{
+		Name:  "light.hardtimeout",
+		Usage: "Time after which a server not answering a light client request is dropped (0 = default)",
+		Value: ethconfig.Defaults.LightHardTimeout,
+	}
+	LightTxStatusRetriesFlag = cli.IntFlag{
+		Name:  "light.txstatusretries",
+		Usage: "Number of retries made retrieving an unknown transaction status (0 = default)",
+		Value: ethconfig.Defaults.LightTxStatusRetries,
+	}
+	LightTxStatusQuorumFlag = cli.IntFlag{
+		Name:  "light.txstatusquorum",
+		Usage: "Number of servers required to report the same transaction status (0 = trust the first)",
+		Value: ethconfig.Defaults.LightTxStatusQuorum,
+	}
+	LightStateHistoryFlag = cli.Uint64Flag{
+		Name:  "light.statehistory",
+		Usage: "Number of recent blocks whose state is accessible by the light client (0 = unlimited)",
+		Value: ethconfig.Defaults.LightStateHistory,
+	}
+	LightCheckpointFlag = cli.StringFlag{
+		Name:  "light.checkpoint",
+		Usage: "Trusted CHT checkpoint to start light syncing from (<sectionIndex>,<sectionHead>,<chtRoot>,<bloomRoot>)",
+	}
 	// Ethash settings
 	EthashCacheDirFlag = DirectoryFlag{
 		Name:  "ethash.cachedir",
@@ -347,6 +418,11 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolJournalLimitFlag = cli.Uint64Flag{
+		Name:  "txpool.journallimit",
+		Usage: "Size in bytes above which the local transaction journal is regenerated early (0 = unlimited)",
+		Value: core.DefaultTxPoolConfig.JournalLimit,
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -354,7 +430,7 @@ var (
 	}
 	TxPoolPriceBumpFlag = cli.Uint64Flag{
 		Name:  "txpool.pricebump",
-		Usage: "Price bump percentage to replace an already existing transaction",
+		Usage: "Price bump percentage to replace an already existing transaction (0 = replace at equal price)",
 		Value: ethconfig.Defaults.TxPool.PriceBump,
 	}
 	TxPoolAccountSlotsFlag = cli.Uint64Flag{
@@ -382,6 +458,11 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.TxPool.Lifetime,
 	}
+	TxPoolPendingLifetimeFlag = cli.DurationFlag{
+		Name:  "txpool.pendinglifetime",
+		Usage: "Maximum amount of time executable transactions are pending (0 = unlimited)",
+		Value: ethconfig.Defaults.TxPool.PendingLifetime,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -497,6 +578,10 @@ var (
 		Name:  "vmdebug",
 		Usage: "Record information useful for VM and contract debugging",
 	}
+	VMOpcodeMetricsFlag = cli.BoolFlag{
+		Name:  "vmopcodemetrics",
+		Usage: "Record per-opcode execution counts, gas and time during block processing",
+	}
 	InsecureUnlockAllowedFlag = cli.BoolFlag{
 		Name:  "allow-insecure-unlock",
 		Usage: "Allow insecure account unlocking when account-related RPCs are exposed by http",
@@ -1033,13 +1118,19 @@ func setLes(ctx *cli.Context, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(LightMaxPeersFlag.Name) {
 		cfg.LightPeers = ctx.GlobalInt(LightMaxPeersFlag.Name)
 	}
+	if ctx.GlobalIsSet(LightMaxPeersPerIPFlag.Name) {
+		cfg.LightPeersPerIP = ctx.GlobalInt(LightMaxPeersPerIPFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightPriorityClientsFlag.Name) {
+		cfg.LightPriorityClients = SplitAndTrim(ctx.GlobalString(LightPriorityClientsFlag.Name))
+	}
 	if ctx.GlobalIsSet(UltraLightServersFlag.Name) {
 		cfg.UltraLightServers = strings.Split(ctx.GlobalString(UltraLightServersFlag.Name), ",")
 	}
 	if ctx.GlobalIsSet(UltraLightFractionFlag.Name) {
 		cfg.UltraLightFraction = ctx.GlobalInt(UltraLightFractionFlag.Name)
 	}
-	if cfg.UltraLightFraction <= 0 && cfg.UltraLightFraction > 100 {
+	if cfg.UltraLightFraction <= 0 || cfg.UltraLightFraction > 100 {
 		log.Error("Ultra light fraction is invalid", "had", cfg.UltraLightFraction, "updated", ethconfig.Defaults.UltraLightFraction)
 		cfg.UltraLightFraction = ethconfig.Defaults.UltraLightFraction
 	}
@@ -1052,6 +1143,28 @@ func setLes(ctx *cli.Context, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(LightNoSyncServeFlag.Name) {
 		cfg.LightNoSyncServe = ctx.GlobalBool(LightNoSyncServeFlag.Name)
 	}
+	if ctx.GlobalIsSet(LightSoftTimeoutFlag.Name) {
+		cfg.LightSoftTimeout = ctx.GlobalDuration(LightSoftTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightHardTimeoutFlag.Name) {
+		cfg.LightHardTimeout = ctx.GlobalDuration(LightHardTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightTxStatusRetriesFlag.Name) {
+		cfg.LightTxStatusRetries = ctx.GlobalInt(LightTxStatusRetriesFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightTxStatusQuorumFlag.Name) {
+		cfg.LightTxStatusQuorum = ctx.GlobalInt(LightTxStatusQuorumFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightStateHistoryFlag.Name) {
+		cfg.LightStateHistory = ctx.GlobalUint64(LightStateHistoryFlag.Name)
+	}
+	if ctx.GlobalIsSet(LightCheckpointFlag.Name) {
+		checkpoint, err := parseCheckpoint(ctx.GlobalString(LightCheckpointFlag.Name))
+		if err != nil {
+			Fatalf("Invalid %s: %v", LightCheckpointFlag.Name, err)
+		}
+		cfg.Checkpoint, cfg.SyncFromCheckpoint = checkpoint, true
+	}
 }
 
 // MakeDatabaseHandles raises out the number of allowed file handles per process
@@ -1328,6 +1441,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolJournalLimitFlag.Name) {
+		cfg.JournalLimit = ctx.GlobalUint64(TxPoolJournalLimitFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1349,6 +1465,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPendingLifetimeFlag.Name) {
+		cfg.PendingLifetime = ctx.GlobalDuration(TxPoolPendingLifetimeFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1426,6 +1545,29 @@ func setWhitelist(ctx *cli.Context, cfg *ethconfig.Config) {
 	}
 }
 
+// parseCheckpoint parses a trusted checkpoint in the format of
+// <sectionIndex>,<sectionHead>,<chtRoot>,<bloomRoot>.
+func parseCheckpoint(spec string) (*params.TrustedCheckpoint, error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("expected 4 comma separated fields, have %d", len(parts))
+	}
+	index, err := strconv.ParseUint(parts[0], 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid section index %s: %v", parts[0], err)
+	}
+	checkpoint := &params.TrustedCheckpoint{SectionIndex: index}
+	for i, field := range []*common.Hash{&checkpoint.SectionHead, &checkpoint.CHTRoot, &checkpoint.BloomRoot} {
+		if err := field.UnmarshalText([]byte(parts[i+1])); err != nil {
+			return nil, fmt.Errorf("invalid hash %s: %v", parts[i+1], err)
+		}
+	}
+	if checkpoint.Empty() {
+		return nil, errors.New("checkpoint is empty")
+	}
+	return checkpoint, nil
+}
+
 // CheckExclusive verifies that only a single instance of the provided flags was
 // set by the user. Each flag might optionally be followed by a string type to
 // specialize it further.
@@ -1544,6 +1686,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(BadBlockReportFlag.Name) {
+		cfg.BadBlockReportURL = ctx.GlobalString(BadBlockReportFlag.Name)
+	}
+	if ctx.GlobalIsSet(AddressTxIndexFlag.Name) {
+		cfg.AddressTxIndex = ctx.GlobalBool(AddressTxIndexFlag.Name)
+	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
@@ -1575,6 +1723,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
 	}
+	if ctx.GlobalIsSet(VMOpcodeMetricsFlag.Name) {
+		cfg.EnableOpcodeMetrics = ctx.GlobalBool(VMOpcodeMetricsFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.GlobalUint64(RPCGlobalGasCapFlag.Name)
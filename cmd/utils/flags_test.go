@@ -20,6 +20,9 @@ package utils
 import (
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func Test_SplitTagsFlag(t *testing.T) {
@@ -62,3 +65,35 @@ func Test_SplitTagsFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCheckpoint(t *testing.T) {
+	var (
+		head  = "0xb583a0ead70324849c4caf923476de3645c0d2f707c86221ec8e40078bdd6884"
+		cht   = "0x6ecc993baad0c9f77fe9c4c13b89360112e5a0accae4d8502470b911211618b7"
+		bloom = "0x66a30d8885c19921711704921de7b4bcbd1b49191b197ee79e34dafeed9a04d9"
+	)
+	want := &params.TrustedCheckpoint{
+		SectionIndex: 384,
+		SectionHead:  common.HexToHash(head),
+		CHTRoot:      common.HexToHash(cht),
+		BloomRoot:    common.HexToHash(bloom),
+	}
+	have, err := parseCheckpoint("384," + head + "," + cht + "," + bloom)
+	if err != nil {
+		t.Fatalf("failed to parse checkpoint: %v", err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("checkpoint mismatch: have %+v, want %+v", have, want)
+	}
+	for _, spec := range []string{
+		"",
+		"384," + head + "," + cht,
+		"abc," + head + "," + cht + "," + bloom,
+		"384," + head + ",0x1234," + bloom,
+		"384,0x0000000000000000000000000000000000000000000000000000000000000000," + cht + "," + bloom,
+	} {
+		if _, err := parseCheckpoint(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
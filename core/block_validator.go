@@ -19,6 +19,7 @@ package core
 import (
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -54,7 +55,38 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if v.bc.HasBlockAndState(block.Hash(), block.NumberU64()) {
 		return ErrKnownBlock
 	}
-	// Header validity is known at this point, check the uncles and transactions
+	if err := v.validateContent(block); err != nil {
+		return err
+	}
+	return v.validateAncestor(block)
+}
+
+// ValidateBodyConcurrently runs the same checks as ValidateBody, but if the
+// block is linkable to the local chain, the validation of its uncles and its
+// transaction root is started in the background with the result delivered on
+// the returned channel. This permits overlapping it with the state transition.
+func (v *BlockValidator) ValidateBodyConcurrently(block *types.Block) (<-chan error, error) {
+	// Check whether the block's known, and if not, that it's linkable
+	if v.bc.HasBlockAndState(block.Hash(), block.NumberU64()) {
+		return nil, ErrKnownBlock
+	}
+	if err := v.validateAncestor(block); err != nil {
+		// Content errors take precedence over ancestry ones, same as in ValidateBody
+		if err := v.validateContent(block); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+	result := make(chan error, 1)
+	go func() {
+		result <- v.validateContent(block)
+	}()
+	return result, nil
+}
+
+// validateContent checks the uncles and the transaction and uncle roots of the
+// given block. The header validity is assumed to be known at this point.
+func (v *BlockValidator) validateContent(block *types.Block) error {
 	header := block.Header()
 	if err := v.engine.VerifyUncles(v.bc, block); err != nil {
 		return err
@@ -65,6 +97,12 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
+	return nil
+}
+
+// validateAncestor checks that the parent of the given block, along with its
+// state, is available in the local chain.
+func (v *BlockValidator) validateAncestor(block *types.Block) error {
 	if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
 		if !v.bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
 			return consensus.ErrUnknownAncestor
@@ -89,14 +127,20 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 	if rbloom != header.Bloom {
 		return fmt.Errorf("invalid bloom (remote: %x  local: %x)", header.Bloom, rbloom)
 	}
-	// Tre receipt Trie's root (R = (Tr [[H1, R1], ... [Hn, Rn]]))
-	receiptSha := types.DeriveSha(receipts, trie.NewStackTrie(nil))
-	if receiptSha != header.ReceiptHash {
+	// Tre receipt Trie's root (R = (Tr [[H1, R1], ... [Hn, Rn]])), derived in the
+	// background while the state root is being hashed
+	receiptShaCh := make(chan common.Hash, 1)
+	go func() {
+		receiptShaCh <- types.DeriveSha(receipts, trie.NewStackTrie(nil))
+	}()
+	root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number))
+
+	if receiptSha := <-receiptShaCh; receiptSha != header.ReceiptHash {
 		return fmt.Errorf("invalid receipt root hash (remote: %x local: %x)", header.ReceiptHash, receiptSha)
 	}
 	// Validate the state root against the received state root and throw
 	// an error if they don't match.
-	if root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number)); header.Root != root {
+	if header.Root != root {
 		return fmt.Errorf("invalid merkle root (remote: %x local: %x)", header.Root, root)
 	}
 	return nil
@@ -129,6 +129,7 @@ type CacheConfig struct {
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
+	AddressTxIndex      bool          // Whether to index canonical transactions by sender and recipient
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -178,6 +179,8 @@ type BlockChain struct {
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	reorgFeed     event.Feed
+	badBlockFeed  event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
 	scope         event.SubscriptionScope
@@ -373,6 +376,16 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		}
 		bc.snaps, _ = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, head.Root(), !bc.cacheConfig.SnapshotWait, true, recover)
 	}
+	// If the address index was just enabled, none of the existing blocks are indexed
+	// yet, they will be backfilled by the transaction indexer. If it's disabled, the
+	// index is no longer maintained and needs to be rebuilt when enabled again.
+	if bc.cacheConfig.AddressTxIndex {
+		if rawdb.ReadAddressTxIndexTail(bc.db) == nil {
+			rawdb.WriteAddressTxIndexTail(bc.db, bc.CurrentFastBlock().NumberU64()+1)
+		}
+	} else if rawdb.ReadAddressTxIndexTail(bc.db) != nil {
+		rawdb.DeleteAddressTxIndexTail(bc.db)
+	}
 	// Take ownership of this particular state
 	go bc.update()
 	if txLookupLimit != nil {
@@ -581,6 +594,12 @@ func (bc *BlockChain) SetHeadBeyondRoot(head uint64, root common.Hash) (uint64,
 	}
 	// Rewind the header chain, deleting all block bodies until then
 	delFn := func(db ethdb.KeyValueWriter, hash common.Hash, num uint64) {
+		// Drop the address index entries while the block body is still around
+		if bc.cacheConfig.AddressTxIndex {
+			if block := rawdb.ReadBlock(bc.db, hash, num); block != nil {
+				rawdb.DeleteAddressTxEntriesByBlock(db, block, types.MakeSigner(bc.chainConfig, block.Number()))
+			}
+		}
 		// Ignore the error here since light client won't hit this path
 		frozen, _ := bc.db.Ancients()
 		if num+1 <= frozen {
@@ -763,6 +782,64 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	return nil
 }
 
+// ExportedBlock is an entry of a chain export carrying, besides the block itself,
+// its receipts and total difficulty, allowing it to be imported without being
+// re-executed.
+type ExportedBlock struct {
+	Block    *types.Block
+	Receipts []*types.ReceiptForStorage
+	TD       *big.Int
+}
+
+// ExportWithReceiptsN writes a subset of the active chain to the given writer,
+// bundling every block with its receipts and total difficulty. Contrary to
+// ExportN the blocks are only required to be fast synced.
+func (bc *BlockChain) ExportWithReceiptsN(w io.Writer, first uint64, last uint64) error {
+	bc.chainmu.RLock()
+	defer bc.chainmu.RUnlock()
+
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	log.Info("Exporting batch of blocks with receipts", "count", last-first+1)
+
+	start, reported := time.Now(), time.Now()
+	for nr := first; nr <= last; nr++ {
+		block := bc.GetBlockByNumber(nr)
+		if block == nil {
+			return fmt.Errorf("export failed on #%d: not found", nr)
+		}
+		receipts := bc.GetReceiptsByHash(block.Hash())
+		if receipts == nil && len(block.Transactions()) > 0 {
+			return fmt.Errorf("export failed on #%d: receipts not found", nr)
+		}
+		td := bc.GetTd(block.Hash(), nr)
+		if td == nil {
+			return fmt.Errorf("export failed on #%d: total difficulty not found", nr)
+		}
+		entry := &ExportedBlock{Block: block, Receipts: make([]*types.ReceiptForStorage, len(receipts)), TD: td}
+		for i, receipt := range receipts {
+			entry.Receipts[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		if err := rlp.Encode(w, entry); err != nil {
+			return err
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Exporting blocks", "exported", block.NumberU64()-first, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	return nil
+}
+
+// writeAddressTxEntries indexes the transactions of a canonical block by address,
+// if the address index is enabled.
+func (bc *BlockChain) writeAddressTxEntries(db ethdb.KeyValueWriter, block *types.Block) {
+	if bc.cacheConfig.AddressTxIndex {
+		rawdb.WriteAddressTxEntriesByBlock(db, block, types.MakeSigner(bc.chainConfig, block.Number()))
+	}
+}
+
 // writeHeadBlock injects a new head block into the current block chain. This method
 // assumes that the block is indeed a true head. It will also reset the head
 // header and the head fast sync block to this very same block if they are older
@@ -777,6 +854,7 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	batch := bc.db.NewBatch()
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	bc.writeAddressTxEntries(batch, block)
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	// If the block is better than our head or is on a different chain, force update heads
@@ -1233,8 +1311,10 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			// generated.
 			if bc.txLookupLimit == 0 || ancientLimit <= bc.txLookupLimit || block.NumberU64() >= ancientLimit-bc.txLookupLimit {
 				rawdb.WriteTxLookupEntriesByBlock(batch, block)
+				bc.writeAddressTxEntries(batch, block)
 			} else if rawdb.ReadTxIndexTail(bc.db) != nil {
 				rawdb.WriteTxLookupEntriesByBlock(batch, block)
+				bc.writeAddressTxEntries(batch, block)
 			}
 			stats.processed++
 		}
@@ -1319,6 +1399,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
 			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receiptChain[i])
 			rawdb.WriteTxLookupEntriesByBlock(batch, block) // Always write tx indices for live blocks, we assume they are needed
+			bc.writeAddressTxEntries(batch, block)
 
 			// Write everything belongs to the blocks into the database. So that
 			// we can ensure all components of body is completed(body, receipts,
@@ -1364,6 +1445,10 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			} else {
 				rawdb.WriteTxIndexTail(bc.db, ancientLimit-bc.txLookupLimit)
 			}
+			// The address index was written for the same ancient blocks
+			if bc.cacheConfig.AddressTxIndex {
+				rawdb.WriteAddressTxIndexTail(bc.db, *rawdb.ReadTxIndexTail(bc.db))
+			}
 		}
 	}
 	if len(liveBlocks) > 0 {
@@ -1425,13 +1510,20 @@ func (bc *BlockChain) writeKnownBlock(block *types.Block) error {
 	bc.wg.Add(1)
 	defer bc.wg.Done()
 
-	current := bc.CurrentBlock()
+	var (
+		current = bc.CurrentBlock()
+		event   *ReorgEvent
+		err     error
+	)
 	if block.ParentHash() != current.Hash() {
-		if err := bc.reorg(current, block); err != nil {
+		if event, err = bc.reorg(current, block); err != nil {
 			return err
 		}
 	}
 	bc.writeHeadBlock(block)
+	if event != nil {
+		bc.reorgFeed.Send(*event)
+	}
 	return nil
 }
 
@@ -1548,10 +1640,11 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 			reorg = !currentPreserve && (blockPreserve || mrand.Float64() < 0.5)
 		}
 	}
+	var reorgEvent *ReorgEvent
 	if reorg {
 		// Reorganise the chain if the parent is not the head block
 		if block.ParentHash() != currentBlock.Hash() {
-			if err := bc.reorg(currentBlock, block); err != nil {
+			if reorgEvent, err = bc.reorg(currentBlock, block); err != nil {
 				return NonStatTy, err
 			}
 		}
@@ -1563,6 +1656,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	if status == CanonStatTy {
 		bc.writeHeadBlock(block)
 	}
+	if reorgEvent != nil {
+		bc.reorgFeed.Send(*reorgEvent)
+	}
 	bc.futureBlocks.Remove(block.Hash())
 
 	if status == CanonStatTy {
@@ -1777,7 +1873,10 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		}
 	}()
 
-	for ; block != nil && err == nil || err == ErrKnownBlock; block, err = it.next() {
+	// The first block of the batch is already fully validated, the body contents
+	// of the subsequent ones are validated concurrently with their execution.
+	var bodyResult <-chan error
+	for ; block != nil && err == nil || err == ErrKnownBlock; block, bodyResult, err = it.nextConcurrent() {
 		// If the chain is terminating, stop processing blocks
 		if bc.insertStopped() {
 			log.Debug("Abort during block processing")
@@ -1848,8 +1947,12 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 			if followup, err := it.peek(); followup != nil && err == nil {
 				throwaway, _ := state.New(parent.Root, bc.stateCache, bc.snaps)
 
+				// Speculative executions must not be accounted as imported ones
+				prefetchConfig := bc.vmConfig
+				prefetchConfig.OpcodeStats = nil
+
 				go func(start time.Time, followup *types.Block, throwaway *state.StateDB, interrupt *uint32) {
-					bc.prefetcher.Prefetch(followup, throwaway, bc.vmConfig, &followupInterrupt)
+					bc.prefetcher.Prefetch(followup, throwaway, prefetchConfig, &followupInterrupt)
 
 					blockPrefetchExecuteTimer.Update(time.Since(start))
 					if atomic.LoadUint32(interrupt) == 1 {
@@ -1861,6 +1964,14 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		// Process block using the parent state as reference point
 		substart := time.Now()
 		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+
+		// Wait for the body validation running alongside the execution. Its errors
+		// take precedence, as an invalid body may well cause failed execution.
+		if bodyResult != nil {
+			if bodyErr := <-bodyResult; bodyErr != nil {
+				err = bodyErr
+			}
+		}
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			atomic.StoreUint32(&followupInterrupt, 1)
@@ -2086,7 +2197,10 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 // reorg takes two blocks, an old chain and a new chain and will reconstruct the
 // blocks and inserts them to be part of the new canonical chain and accumulates
 // potential missing transactions and post an event about them.
-func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
+//
+// The returned reorg event, if any, must only be sent once the new head block
+// is written, so subscribers see the new canonical chain.
+func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) (*ReorgEvent, error) {
 	var (
 		newChain    types.Blocks
 		oldChain    types.Blocks
@@ -2156,10 +2270,10 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		}
 	}
 	if oldBlock == nil {
-		return fmt.Errorf("invalid old chain")
+		return nil, fmt.Errorf("invalid old chain")
 	}
 	if newBlock == nil {
-		return fmt.Errorf("invalid new chain")
+		return nil, fmt.Errorf("invalid new chain")
 	}
 	// Both sides of the reorg are at the same number, reduce both until the common
 	// ancestor is found
@@ -2179,11 +2293,11 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		// Step back with both chains
 		oldBlock = bc.GetBlock(oldBlock.ParentHash(), oldBlock.NumberU64()-1)
 		if oldBlock == nil {
-			return fmt.Errorf("invalid old chain")
+			return nil, fmt.Errorf("invalid old chain")
 		}
 		newBlock = bc.GetBlock(newBlock.ParentHash(), newBlock.NumberU64()-1)
 		if newBlock == nil {
-			return fmt.Errorf("invalid new chain")
+			return nil, fmt.Errorf("invalid new chain")
 		}
 	}
 	// Ensure the user sees large reorgs
@@ -2202,6 +2316,17 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	} else {
 		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(), "oldhash", oldBlock.Hash(), "newnum", newBlock.Number(), "newhash", newBlock.Hash())
 	}
+	// Drop the address index entries of the old chain before the new chain
+	// overwrites the same positions.
+	if bc.cacheConfig.AddressTxIndex {
+		batch := bc.db.NewBatch()
+		for _, block := range oldChain {
+			rawdb.DeleteAddressTxEntriesByBlock(batch, block, types.MakeSigner(bc.chainConfig, block.Number()))
+		}
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to delete stale address indexes", "err", err)
+		}
+	}
 	// Insert the new chain(except the head block(reverse order)),
 	// taking care of the proper incremental order.
 	for i := len(newChain) - 1; i >= 1; i-- {
@@ -2247,7 +2372,31 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			bc.chainSideFeed.Send(ChainSideEvent{Block: oldChain[i]})
 		}
 	}
-	return nil
+	if len(oldChain) > 0 && len(newChain) > 0 {
+		event := newReorgEvent(commonBlock, oldChain, newChain, deletedTxs, append(addedTxs, newChain[0].Transactions()...))
+		return &event, nil
+	}
+	return nil, nil
+}
+
+// newReorgEvent assembles the event announcing a chain reorganisation. Both
+// chain segments are expected in descending order, as gathered by reorg, with
+// the new head block included in the added transactions.
+func newReorgEvent(ancestor *types.Block, oldChain, newChain types.Blocks, deletedTxs, addedTxs types.Transactions) ReorgEvent {
+	event := ReorgEvent{
+		Ancestor:   ancestor.Hash(),
+		Dropped:    make([]common.Hash, 0, len(oldChain)),
+		Added:      make([]common.Hash, 0, len(newChain)),
+		DroppedTxs: types.TxDifference(deletedTxs, addedTxs),
+		AddedTxs:   types.TxDifference(addedTxs, deletedTxs),
+	}
+	for _, block := range oldChain {
+		event.Dropped = append(event.Dropped, block.Hash())
+	}
+	for i := len(newChain) - 1; i >= 0; i-- {
+		event.Added = append(event.Added, newChain[i].Hash())
+	}
+	return event
 }
 
 func (bc *BlockChain) update() {
@@ -2291,6 +2440,11 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 	indexBlocks := func(tail *uint64, head uint64, done chan struct{}) {
 		defer func() { done <- struct{}{} }()
 
+		// Move the address index along once the transaction index is updated
+		if bc.cacheConfig.AddressTxIndex {
+			defer bc.maintainAddressTxIndex()
+		}
+
 		// If the user just upgraded Geth to a new version which supports transaction
 		// index pruning, write the new tail and remove anything older.
 		if tail == nil {
@@ -2349,9 +2503,26 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 	}
 }
 
+// maintainAddressTxIndex moves the tail of the address index to the one of the
+// transaction lookup index. This backfills the blocks that existed before the
+// address index was enabled and prunes the entries of the blocks unindexed due
+// to `txlookuplimit`.
+func (bc *BlockChain) maintainAddressTxIndex() {
+	target, tail := rawdb.ReadTxIndexTail(bc.db), rawdb.ReadAddressTxIndexTail(bc.db)
+	if target == nil || tail == nil {
+		return
+	}
+	if *target < *tail {
+		rawdb.IndexAddressTransactions(bc.db, *target, *tail, bc.chainConfig, bc.quit)
+	} else if *target > *tail {
+		rawdb.UnindexAddressTransactions(bc.db, *tail, *target, bc.chainConfig, bc.quit)
+	}
+}
+
 // reportBlock logs a bad block error.
 func (bc *BlockChain) reportBlock(block *types.Block, receipts types.Receipts, err error) {
-	rawdb.WriteBadBlock(bc.db, block)
+	rawdb.WriteBadBlock(bc.db, block, err)
+	bc.badBlockFeed.Send(BadBlockEvent{Block: block, Err: err})
 
 	var receiptString string
 	for i, receipt := range receipts {
@@ -2495,6 +2666,16 @@ func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Su
 	return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeReorgEvent registers a subscription of ReorgEvent.
+func (bc *BlockChain) SubscribeReorgEvent(ch chan<- ReorgEvent) event.Subscription {
+	return bc.scope.Track(bc.reorgFeed.Subscribe(ch))
+}
+
+// SubscribeBadBlockEvent registers a subscription of BadBlockEvent.
+func (bc *BlockChain) SubscribeBadBlockEvent(ch chan<- BadBlockEvent) event.Subscription {
+	return bc.scope.Track(bc.badBlockFeed.Subscribe(ch))
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Subscription {
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
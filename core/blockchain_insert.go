@@ -104,6 +104,31 @@ func newInsertIterator(chain types.Blocks, results <-chan error, validator Valid
 // next returns the next block in the iterator, along with any potential validation
 // error for that block. When the end is reached, it will return (nil, nil).
 func (it *insertIterator) next() (*types.Block, error) {
+	block, err := it.advance()
+	if block == nil || err != nil {
+		return block, err
+	}
+	// Block header valid, run body validation and return
+	return block, it.validator.ValidateBody(block)
+}
+
+// nextConcurrent is like next, but if the block is linkable to the local chain,
+// the validation of its body content is only started in the background, with
+// the result delivered on the returned channel. The result needs to be checked
+// before the block is committed.
+func (it *insertIterator) nextConcurrent() (*types.Block, <-chan error, error) {
+	block, err := it.advance()
+	if block == nil || err != nil {
+		return block, nil, err
+	}
+	// Block header valid, start body validation and return
+	result, err := it.validator.ValidateBodyConcurrently(block)
+	return block, result, err
+}
+
+// advance moves the iterator to the next block, returning it along with any
+// header verification error. When the end is reached, it will return (nil, nil).
+func (it *insertIterator) advance() (*types.Block, error) {
 	// If we reached the end of the chain, abort
 	if it.index+1 >= len(it.chain) {
 		it.index = len(it.chain)
@@ -114,11 +139,7 @@ func (it *insertIterator) next() (*types.Block, error) {
 	if len(it.errors) <= it.index {
 		it.errors = append(it.errors, <-it.results)
 	}
-	if it.errors[it.index] != nil {
-		return it.chain[it.index], it.errors[it.index]
-	}
-	// Block header valid, run body validation and return
-	return it.chain[it.index], it.validator.ValidateBody(it.chain[it.index])
+	return it.chain[it.index], it.errors[it.index]
 }
 
 // peek returns the next block in the iterator, along with any potential validation
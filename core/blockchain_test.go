@@ -17,12 +17,15 @@
 package core
 
 import (
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -944,6 +947,64 @@ func TestChainTxReorgs(t *testing.T) {
 	}
 }
 
+// Tests that the address transaction index follows chain reorganisations.
+func TestAddressTxIndexReorg(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = common.BytesToAddress([]byte{0x02})
+		addr3   = common.BytesToAddress([]byte{0x03})
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{
+			Config:   params.TestChainConfig,
+			GasLimit: 3141592,
+			Alloc:    GenesisAlloc{addr1: {Balance: big.NewInt(1000000000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	var dropped, added *types.Transaction
+
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, gen *BlockGen) {
+		if i == 0 {
+			dropped, _ = types.SignTx(types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(1000), params.TxGas, gen.header.BaseFee, nil), signer, key1)
+			gen.AddTx(dropped)
+		}
+		gen.OffsetTime(9) // Lower the block difficulty to simulate a weaker chain
+	})
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.AddressTxIndex = true
+
+	blockchain, _ := NewBlockChain(db, &cacheConfig, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if i, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert original chain[%d]: %v", i, err)
+	}
+	defer blockchain.Stop()
+
+	if entries := rawdb.ReadAddressTxEntries(db, addr2, 0, 0, 10); len(entries) != 1 || entries[0].Hash != dropped.Hash() {
+		t.Fatalf("recipient index mismatch before reorg: %v", entries)
+	}
+	// Overwrite the old chain with a heavier one moving the funds elsewhere
+	chain, _ = GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		if i == 0 {
+			added, _ = types.SignTx(types.NewTransaction(gen.TxNonce(addr1), addr3, big.NewInt(1000), params.TxGas, gen.header.BaseFee, nil), signer, key1)
+			gen.AddTx(added)
+		}
+	})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert forked chain: %v", err)
+	}
+	if entries := rawdb.ReadAddressTxEntries(db, addr2, 0, 0, 10); len(entries) != 0 {
+		t.Errorf("dropped recipient still indexed: %v", entries)
+	}
+	if entries := rawdb.ReadAddressTxEntries(db, addr3, 0, 0, 10); len(entries) != 1 || entries[0].Hash != added.Hash() {
+		t.Errorf("added recipient index mismatch: %v", entries)
+	}
+	if entries := rawdb.ReadAddressTxEntries(db, addr1, 0, 0, 10); len(entries) != 1 || entries[0].Hash != added.Hash() {
+		t.Errorf("sender index mismatch: %v", entries)
+	}
+}
+
 func TestLogReorgs(t *testing.T) {
 	var (
 		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -1217,6 +1278,109 @@ done:
 
 }
 
+// Tests that a reorg announces the dropped and added blocks along with the
+// transactions exclusive to either side.
+func TestReorgEvent(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{
+			Config:   params.TestChainConfig,
+			GasLimit: 3141592,
+			Alloc:    GenesisAlloc{addr1: {Balance: big.NewInt(1000000000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	var shared, dropped, added *types.Transaction
+	shared, _ = types.SignTx(types.NewTransaction(0, addr1, big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, key1)
+
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, gen *BlockGen) {
+		switch i {
+		case 0:
+			gen.AddTx(shared)
+		case 1:
+			dropped, _ = types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{0x01}, big.NewInt(1000), params.TxGas, gen.header.BaseFee, nil), signer, key1)
+			gen.AddTx(dropped)
+		}
+		gen.OffsetTime(-9) // Raise the block difficulty so only a longer fork can overtake
+	})
+	if i, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert original chain[%d]: %v", i, err)
+	}
+	forks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		switch i {
+		case 1:
+			gen.AddTx(shared)
+		case 2:
+			added, _ = types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{0x02}, big.NewInt(1000), params.TxGas, gen.header.BaseFee, nil), signer, key1)
+			gen.AddTx(added)
+		}
+	})
+	// Query the canonical chain as soon as the event arrives, subscribers must
+	// already see the new head. The event is delivered to a second subscription
+	// only afterwards, which keeps the chain blocked in sending it meanwhile.
+	reorgCh, holdCh := make(chan ReorgEvent), make(chan ReorgEvent)
+	sub := blockchain.SubscribeReorgEvent(reorgCh)
+	defer sub.Unsubscribe()
+	hold := blockchain.SubscribeReorgEvent(holdCh)
+	defer hold.Unsubscribe()
+
+	type reorgReceipt struct {
+		event     ReorgEvent
+		head      common.Hash
+		canonical common.Hash
+	}
+	receiptCh := make(chan reorgReceipt, 1)
+	go func() {
+		// The feed delivers to the subscriptions in random order
+		var (
+			ev   ReorgEvent
+			rest = holdCh
+		)
+		select {
+		case ev = <-reorgCh:
+		case ev = <-holdCh:
+			rest = reorgCh
+		}
+		receiptCh <- reorgReceipt{ev, blockchain.CurrentBlock().Hash(), rawdb.ReadCanonicalHash(db, forks[2].NumberU64())}
+		<-rest
+	}()
+	if i, err := blockchain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert forked chain[%d]: %v", i, err)
+	}
+	select {
+	case receipt := <-receiptCh:
+		ev := receipt.event
+		if receipt.head != forks[2].Hash() || receipt.canonical != forks[2].Hash() {
+			t.Errorf("new head not canonical on event receipt: head %x, canonical %x, want %x", receipt.head, receipt.canonical, forks[2].Hash())
+		}
+		if ev.Ancestor != genesis.Hash() {
+			t.Errorf("ancestor mismatch: have %x, want %x", ev.Ancestor, genesis.Hash())
+		}
+		wantDropped := []common.Hash{chain[1].Hash(), chain[0].Hash()}
+		if !reflect.DeepEqual(ev.Dropped, wantDropped) {
+			t.Errorf("dropped blocks mismatch: have %x, want %x", ev.Dropped, wantDropped)
+		}
+		wantAdded := []common.Hash{forks[0].Hash(), forks[1].Hash(), forks[2].Hash()}
+		if !reflect.DeepEqual(ev.Added, wantAdded) {
+			t.Errorf("added blocks mismatch: have %x, want %x", ev.Added, wantAdded)
+		}
+		if len(ev.DroppedTxs) != 1 || ev.DroppedTxs[0].Hash() != dropped.Hash() {
+			t.Errorf("dropped transactions mismatch: have %v, want [%x]", ev.DroppedTxs, dropped.Hash())
+		}
+		if len(ev.AddedTxs) != 1 || ev.AddedTxs[0].Hash() != added.Hash() {
+			t.Errorf("added transactions mismatch: have %v, want [%x]", ev.AddedTxs, added.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reorg event")
+	}
+}
+
 // Tests if the canonical block can be fetched from the database during chain insertion.
 func TestCanonicalBlockRetrieval(t *testing.T) {
 	_, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
@@ -2295,6 +2459,113 @@ func TestSkipStaleTxIndicesInFastSync(t *testing.T) {
 	check(&tail, chain)
 }
 
+// Tests that the address index is written for fast synced blocks, backfilled
+// when enabled on an existing database and pruned along with the tx indices.
+func TestAddressTxIndexMaintenance(t *testing.T) {
+	// Configure and generate a sample block chain
+	var (
+		gendb   = rawdb.NewMemoryDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(100000000000000000)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{address: {Balance: funds}}}
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	height := uint64(128)
+	blocks, receipts := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), gendb, int(height), func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x00}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	blocks2, _ := GenerateChain(gspec.Config, blocks[len(blocks)-1], ethash.NewFaker(), gendb, 10, nil)
+
+	// check verifies that exactly the transactions of the blocks from tail on
+	// are indexed for both the sender and the recipient.
+	check := func(chain *BlockChain, tail uint64) {
+		t.Helper()
+		if stored := rawdb.ReadAddressTxIndexTail(chain.db); stored == nil || *stored != tail {
+			t.Fatalf("address index tail mismatch: have %v, want %d", stored, tail)
+		}
+		first := tail
+		if first == 0 {
+			first = 1 // genesis has no transactions
+		}
+		for _, addr := range []common.Address{address, {0x00}} {
+			entries := rawdb.ReadAddressTxEntries(chain.db, addr, 0, 0, 1000)
+			if len(entries) != int(height-first+1) {
+				t.Fatalf("address %x: entry count mismatch: have %d, want %d", addr, len(entries), height-first+1)
+			}
+			if entries[0].BlockNumber != first {
+				t.Fatalf("address %x: oldest entry mismatch: have #%d, want #%d", addr, entries[0].BlockNumber, first)
+			}
+		}
+	}
+	frdir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temp freezer dir: %v", err)
+	}
+	defer os.Remove(frdir)
+	ancientDb, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), frdir, "", false)
+	if err != nil {
+		t.Fatalf("failed to create temp freezer db: %v", err)
+	}
+	gspec.MustCommit(ancientDb)
+
+	// Fast sync all blocks, only HEAD-32 indices are kept.
+	newChain := func(limit uint64, index bool) *BlockChain {
+		cacheConfig := *defaultCacheConfig
+		cacheConfig.AddressTxIndex = index
+
+		chain, err := NewBlockChain(ancientDb, &cacheConfig, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, &limit)
+		if err != nil {
+			t.Fatalf("failed to create tester chain: %v", err)
+		}
+		return chain
+	}
+	chain := newChain(32, true)
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if n, err := chain.InsertHeaderChain(headers, 0); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	if n, err := chain.InsertReceiptChain(blocks, receipts, 64); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	check(chain, 32)
+	chain.Stop()
+
+	// Restore all indices, then drop the stale ones along with the tx indices
+	for i, step := range []struct {
+		limit uint64
+		tail  uint64
+	}{{0, 0}, {64, 67 /* 130 - 64 + 1 */}} {
+		chain = newChain(step.limit, true)
+		chain.InsertChain(blocks2[i : i+1]) // Feed chain a higher block to trigger indices updater.
+		time.Sleep(50 * time.Millisecond)   // Wait for indices initialisation
+		check(chain, step.tail)
+		chain.Stop()
+	}
+	// Run a while without the address index, then enable it again
+	chain = newChain(0, false)
+	chain.InsertChain(blocks2[2:3])
+	time.Sleep(50 * time.Millisecond)
+	if tail := rawdb.ReadAddressTxIndexTail(chain.db); tail != nil {
+		t.Fatalf("address index tail not deleted: %d", *tail)
+	}
+	chain.Stop()
+
+	chain = newChain(0, true)
+	chain.InsertChain(blocks2[3:4])
+	time.Sleep(50 * time.Millisecond)
+	check(chain, 0)
+	chain.Stop()
+}
+
 // Benchmarks large blocks with value transfers to non-existing accounts
 func benchmarkLargeNumberOfValueToNonexisting(b *testing.B, numTxs, numBlocks int, recipientFn func(uint64) common.Address, dataFn func(uint64) []byte) {
 	var (
@@ -3275,3 +3546,109 @@ func TestEIP1559Transition(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// Tests that blocks with invalid bodies are rejected, regardless of whether their
+// contents are validated upfront or concurrently with the block execution.
+func TestInsertInvalidBody(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000000000)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	gendb := rawdb.NewMemoryDatabase()
+	blocks, _ := GenerateChain(gspec.Config, gspec.MustCommit(gendb), ethash.NewFaker(), gendb, 3, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), common.Address{0x01}, big.NewInt(1000), params.TxGas, gen.BaseFee(), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	for bad := range blocks {
+		db := rawdb.NewMemoryDatabase()
+		gspec.MustCommit(db)
+		chain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+
+		// Drop the transactions from the body of one block, keeping its header
+		corrupt := make(types.Blocks, len(blocks))
+		copy(corrupt, blocks)
+		corrupt[bad] = blocks[bad].WithBody(nil, nil)
+
+		n, err := chain.InsertChain(corrupt)
+		if err == nil || !strings.Contains(err.Error(), "transaction root hash mismatch") {
+			t.Errorf("block %d: invalid body error mismatch: have %v", bad, err)
+		}
+		if n != bad {
+			t.Errorf("block %d: failure index mismatch: have %d, want %d", bad, n, bad)
+		}
+		if head := chain.CurrentBlock().NumberU64(); head != uint64(bad) {
+			t.Errorf("block %d: head mismatch: have %d, want %d", bad, head, bad)
+		}
+		chain.Stop()
+	}
+}
+
+// Tests that opcode statistics only account the executions of imported blocks,
+// not the speculative ones of the block prefetcher.
+func TestOpcodeStatsImport(t *testing.T) {
+	var (
+		aa = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				// The address 0xAAAA adds two program counters
+				aa: {
+					Code:    []byte{byte(vm.PC), byte(vm.PC), byte(vm.ADD), byte(vm.POP)},
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	// Send the transactions of each block from a distinct account, so those of
+	// the followup block are valid on top of the parent state and the prefetcher
+	// executes them
+	keys := make([]*ecdsa.PrivateKey, 16)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		gspec.Alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	genesis := gspec.MustCommit(db)
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, len(keys), func(i int, b *BlockGen) {
+		for j := 0; j < 50; j++ {
+			tx, _ := types.SignNewTx(keys[i], signer, &types.LegacyTx{
+				Nonce:    uint64(j),
+				To:       &aa,
+				Gas:      30000,
+				GasPrice: b.header.BaseFee,
+			})
+			b.AddTx(tx)
+		}
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	stats := vm.NewOpcodeStats()
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{OpcodeStats: stats}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	executed := stats.Stats()
+	if have, want := executed["PC"].Count, uint64(2*50*len(blocks)); have != want {
+		t.Errorf("PC count mismatch: have %d, want %d", have, want)
+	}
+	for _, op := range []string{"ADD", "POP"} {
+		if have, want := executed[op].Count, uint64(50*len(blocks)); have != want {
+			t.Errorf("%s count mismatch: have %d, want %d", op, have, want)
+		}
+	}
+}
@@ -17,7 +17,9 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -26,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
 )
 
 const (
@@ -53,6 +56,17 @@ func NewBloomIndexer(db ethdb.Database, size, confirms uint64) *ChainIndexer {
 	}
 	table := rawdb.NewTable(db, string(rawdb.BloomBitsIndexPrefix))
 
+	// Sections indexed with a different size are unusable, drop them if the
+	// section size was reconfigured since the last run
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], size)
+	if stored, _ := table.Get([]byte("size")); !bytes.Equal(stored, enc[:]) {
+		if len(stored) > 0 {
+			log.Warn("Bloom bits section size changed, reindexing", "stored", binary.BigEndian.Uint64(stored), "size", size)
+			table.Delete([]byte("count"))
+		}
+		table.Put([]byte("size"), enc[:])
+	}
 	return NewChainIndexer(db, table, backend, size, confirms, bloomThrottling, "bloombits")
 }
 
@@ -0,0 +1,49 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// Tests that the bloom bits sections indexed so far are only retained if the
+// section size was not reconfigured.
+func TestBloomIndexerSectionSizeChange(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	table := rawdb.NewTable(db, string(rawdb.BloomBitsIndexPrefix))
+
+	var count [8]byte
+	binary.BigEndian.PutUint64(count[:], 3)
+
+	for i, tt := range []struct {
+		size uint64
+		kept bool
+	}{{4096, true}, {4096, true}, {1024, false}} {
+		table.Put([]byte("count"), count[:])
+
+		indexer := NewBloomIndexer(db, tt.size, 256)
+		sections, _, _ := indexer.Sections()
+		indexer.Close()
+
+		if kept := sections == 3; kept != tt.kept {
+			t.Errorf("test %d: sections retention mismatch: have %v, want %v", i, kept, tt.kept)
+		}
+	}
+}
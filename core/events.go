@@ -24,6 +24,10 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// StaleTxsEvent is posted when transactions are dropped from the transaction
+// pool for exceeding their lifetime.
+type StaleTxsEvent struct{ Txs []*types.Transaction }
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
@@ -41,3 +45,21 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// BadBlockEvent is posted when a block fails validation during import.
+type BadBlockEvent struct {
+	Block *types.Block
+	Err   error
+}
+
+// ReorgEvent is posted when the canonical chain is reorganised. Dropped holds
+// the blocks removed from the canonical chain starting from the old head, Added
+// the newly canonical blocks in import order. The transaction lists only hold
+// transactions exclusive to one side of the reorg.
+type ReorgEvent struct {
+	Ancestor   common.Hash   // Hash of the common ancestor of both chains
+	Dropped    []common.Hash // Hashes of the blocks removed from the canonical chain
+	Added      []common.Hash // Hashes of the blocks added to the canonical chain
+	DroppedTxs []*types.Transaction
+	AddedTxs   []*types.Transaction
+}
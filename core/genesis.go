@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
@@ -158,7 +159,49 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	return SetupGenesisBlockWithOverride(db, genesis, nil)
 }
 
-func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, overrideLondon *big.Int) (*params.ChainConfig, common.Hash, error) {
+// ChainOverrides contains the fork blocks to set on top of the chain configuration
+// of an already initialized database, allowing forks to be delayed or tested
+// without editing the genesis and resyncing.
+type ChainOverrides struct {
+	Byzantium      *big.Int `toml:",omitempty"`
+	Constantinople *big.Int `toml:",omitempty"`
+	Petersburg     *big.Int `toml:",omitempty"`
+	Istanbul       *big.Int `toml:",omitempty"`
+	MuirGlacier    *big.Int `toml:",omitempty"`
+	Berlin         *big.Int `toml:",omitempty"`
+	London         *big.Int `toml:",omitempty"`
+}
+
+// apply returns a copy of the given chain configuration with the overridden fork
+// blocks set. The original configuration is returned if there is nothing to set.
+func (o *ChainOverrides) apply(config *params.ChainConfig) *params.ChainConfig {
+	if o == nil || *o == (ChainOverrides{}) {
+		return config
+	}
+	cpy := *config
+	for _, override := range []struct {
+		block *big.Int
+		field **big.Int
+	}{
+		{o.Byzantium, &cpy.ByzantiumBlock},
+		{o.Constantinople, &cpy.ConstantinopleBlock},
+		{o.Petersburg, &cpy.PetersburgBlock},
+		{o.Istanbul, &cpy.IstanbulBlock},
+		{o.MuirGlacier, &cpy.MuirGlacierBlock},
+		{o.Berlin, &cpy.BerlinBlock},
+		{o.London, &cpy.LondonBlock},
+	} {
+		if override.block != nil {
+			*override.field = override.block
+		}
+	}
+	return &cpy
+}
+
+// SetupGenesisBlockWithOverride is SetupGenesisBlock, but additionally applies
+// the given fork block overrides to the chain configuration of an existing
+// database.
+func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, overrides *ChainOverrides) (*params.ChainConfig, common.Hash, error) {
 	if genesis != nil && genesis.Config == nil {
 		return params.AllEthashProtocolChanges, common.Hash{}, errGenesisNoConfig
 	}
@@ -203,13 +246,16 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 		}
 	}
 	// Get the existing chain configuration.
-	newcfg := genesis.configOrDefault(stored)
-	if overrideLondon != nil {
-		newcfg.LondonBlock = overrideLondon
-	}
+	newcfg := overrides.apply(genesis.configOrDefault(stored))
 	if err := newcfg.CheckConfigForkOrder(); err != nil {
 		return newcfg, common.Hash{}, err
 	}
+	if err := vm.CheckCustomPrecompiles(newcfg); err != nil {
+		return newcfg, common.Hash{}, err
+	}
+	if err := vm.CheckOpcodeGas(newcfg); err != nil {
+		return newcfg, common.Hash{}, err
+	}
 	storedcfg := rawdb.ReadChainConfig(db, stored)
 	if storedcfg == nil {
 		log.Warn("Found genesis block without chain config")
@@ -217,10 +263,15 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 		return newcfg, stored, nil
 	}
 	// Special case: don't change the existing config of a non-mainnet chain if no new
-	// config is supplied. These chains would get AllProtocolChanges (and a compat error)
-	// if we just continued here.
+	// config is supplied, apart from the explicit overrides. These chains would get
+	// AllProtocolChanges (and a compat error) if we just continued here.
 	if genesis == nil && stored != params.MainnetGenesisHash {
-		return storedcfg, stored, nil
+		if newcfg = overrides.apply(storedcfg); newcfg == storedcfg {
+			return storedcfg, stored, nil
+		}
+		if err := newcfg.CheckConfigForkOrder(); err != nil {
+			return newcfg, common.Hash{}, err
+		}
 	}
 	// Check config compatibility and write the config. Compatibility errors
 	// are returned to the caller unless we're already at block zero.
@@ -321,6 +372,12 @@ func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 	if err := config.CheckConfigForkOrder(); err != nil {
 		return nil, err
 	}
+	if err := vm.CheckCustomPrecompiles(config); err != nil {
+		return nil, err
+	}
+	if err := vm.CheckOpcodeGas(config); err != nil {
+		return nil, err
+	}
 	rawdb.WriteTd(db, block.Hash(), block.NumberU64(), g.Difficulty)
 	rawdb.WriteBlock(db, block)
 	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
@@ -53,6 +53,21 @@ func TestSetupGenesis(t *testing.T) {
 		oldcustomg = customg
 	)
 	oldcustomg.Config = &params.ChainConfig{HomesteadBlock: big.NewInt(2)}
+
+	// Genesis with all forks enabled and overridden configs of existing chains
+	forkedConfig := *params.AllEthashProtocolChanges
+	forkedg := Genesis{Config: &forkedConfig}
+
+	overriddenMainnetConfig := *params.MainnetChainConfig
+	overriddenMainnetConfig.LondonBlock = big.NewInt(20000000)
+
+	overriddenForkedConfig := forkedConfig
+	overriddenForkedConfig.BerlinBlock, overriddenForkedConfig.LondonBlock = big.NewInt(10), big.NewInt(20)
+
+	misorderedForkedConfig := forkedConfig
+	misorderedForkedConfig.BerlinBlock, misorderedForkedConfig.LondonBlock = big.NewInt(20), big.NewInt(10)
+	misorderedErr := misorderedForkedConfig.CheckConfigForkOrder()
+
 	tests := []struct {
 		name       string
 		fn         func(ethdb.Database) (*params.ChainConfig, common.Hash, error)
@@ -94,6 +109,33 @@ func TestSetupGenesis(t *testing.T) {
 			wantHash:   customghash,
 			wantConfig: customg.Config,
 		},
+		{
+			name: "mainnet block in DB, genesis == nil, overrides",
+			fn: func(db ethdb.Database) (*params.ChainConfig, common.Hash, error) {
+				DefaultGenesisBlock().MustCommit(db)
+				return SetupGenesisBlockWithOverride(db, nil, &ChainOverrides{London: big.NewInt(20000000)})
+			},
+			wantHash:   params.MainnetGenesisHash,
+			wantConfig: &overriddenMainnetConfig,
+		},
+		{
+			name: "custom block in DB, genesis == nil, overrides",
+			fn: func(db ethdb.Database) (*params.ChainConfig, common.Hash, error) {
+				forkedg.MustCommit(db)
+				return SetupGenesisBlockWithOverride(db, nil, &ChainOverrides{Berlin: big.NewInt(10), London: big.NewInt(20)})
+			},
+			wantHash:   forkedg.ToBlock(nil).Hash(),
+			wantConfig: &overriddenForkedConfig,
+		},
+		{
+			name: "custom block in DB, genesis == nil, misordered overrides",
+			fn: func(db ethdb.Database) (*params.ChainConfig, common.Hash, error) {
+				forkedg.MustCommit(db)
+				return SetupGenesisBlockWithOverride(db, nil, &ChainOverrides{Berlin: big.NewInt(20), London: big.NewInt(10)})
+			},
+			wantErr:    misorderedErr,
+			wantConfig: &misorderedForkedConfig,
+		},
 		{
 			name: "custom block in DB, genesis == ropsten",
 			fn: func(db ethdb.Database) (*params.ChainConfig, common.Hash, error) {
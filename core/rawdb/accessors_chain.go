@@ -252,6 +252,34 @@ func WriteTxIndexTail(db ethdb.KeyValueWriter, number uint64) {
 	}
 }
 
+// ReadAddressTxIndexTail retrieves the number of the oldest block whose
+// transactions have been indexed by address, or nil if the address index
+// is not maintained.
+func ReadAddressTxIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(addressTxIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteAddressTxIndexTail stores the number of the oldest block indexed by
+// address into database.
+func WriteAddressTxIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(addressTxIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the address transaction index tail", "err", err)
+	}
+}
+
+// DeleteAddressTxIndexTail deletes the address index tail, marking the index
+// as no longer maintained.
+func DeleteAddressTxIndexTail(db ethdb.KeyValueWriter) {
+	if err := db.Delete(addressTxIndexTailKey); err != nil {
+		log.Crit("Failed to delete the address transaction index tail", "err", err)
+	}
+}
+
 // ReadFastTxLookupLimit retrieves the tx lookup limit used in fast sync.
 func ReadFastTxLookupLimit(db ethdb.KeyValueReader) *uint64 {
 	data, _ := db.Get(fastTxLookupLimitKey)
@@ -708,6 +736,7 @@ const badBlockToKeep = 10
 type badBlock struct {
 	Header *types.Header
 	Body   *types.Body
+	Reason string `rlp:"optional"` // Validation error, missing for entries written by older versions
 }
 
 // badBlockList implements the sort interface to allow sorting a list of
@@ -738,6 +767,25 @@ func ReadBadBlock(db ethdb.Reader, hash common.Hash) *types.Block {
 	return nil
 }
 
+// ReadBadBlockReason retrieves the validation error the bad block with the
+// corresponding block hash was rejected with.
+func ReadBadBlockReason(db ethdb.Reader, hash common.Hash) string {
+	blob, err := db.Get(badBlockKey)
+	if err != nil {
+		return ""
+	}
+	var badBlocks badBlockList
+	if err := rlp.DecodeBytes(blob, &badBlocks); err != nil {
+		return ""
+	}
+	for _, bad := range badBlocks {
+		if bad.Header.Hash() == hash {
+			return bad.Reason
+		}
+	}
+	return ""
+}
+
 // ReadAllBadBlocks retrieves all the bad blocks in the database.
 // All returned blocks are sorted in reverse order by number.
 func ReadAllBadBlocks(db ethdb.Reader) []*types.Block {
@@ -756,9 +804,10 @@ func ReadAllBadBlocks(db ethdb.Reader) []*types.Block {
 	return blocks
 }
 
-// WriteBadBlock serializes the bad block into the database. If the cumulated
-// bad blocks exceeds the limitation, the oldest will be dropped.
-func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
+// WriteBadBlock serializes the bad block along with the reason of its rejection
+// into the database. If the cumulated bad blocks exceeds the limitation, the
+// oldest will be dropped.
+func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block, reason error) {
 	blob, err := db.Get(badBlockKey)
 	if err != nil {
 		log.Warn("Failed to load old bad blocks", "error", err)
@@ -775,10 +824,14 @@ func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
 			return
 		}
 	}
-	badBlocks = append(badBlocks, &badBlock{
+	bad := &badBlock{
 		Header: block.Header(),
 		Body:   block.Body(),
-	})
+	}
+	if reason != nil {
+		bad.Reason = reason.Error()
+	}
+	badBlocks = append(badBlocks, bad)
 	sort.Sort(sort.Reverse(badBlocks))
 	if len(badBlocks) > badBlockToKeep {
 		badBlocks = badBlocks[:badBlockToKeep]
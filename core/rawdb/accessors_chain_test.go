@@ -19,6 +19,7 @@ package rawdb
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -205,7 +206,7 @@ func TestBadBlockStorage(t *testing.T) {
 		t.Fatalf("Non existent block returned: %v", entry)
 	}
 	// Write and verify the block in the database
-	WriteBadBlock(db, block)
+	WriteBadBlock(db, block, nil)
 	if entry := ReadBadBlock(db, block.Hash()); entry == nil {
 		t.Fatalf("Stored block not found")
 	} else if entry.Hash() != block.Hash() {
@@ -219,10 +220,16 @@ func TestBadBlockStorage(t *testing.T) {
 		TxHash:      types.EmptyRootHash,
 		ReceiptHash: types.EmptyRootHash,
 	})
-	WriteBadBlock(db, blockTwo)
+	WriteBadBlock(db, blockTwo, errors.New("invalid merkle root"))
+	if reason := ReadBadBlockReason(db, blockTwo.Hash()); reason != "invalid merkle root" {
+		t.Fatalf("Retrieved bad block reason mismatch: have %q, want %q", reason, "invalid merkle root")
+	}
+	if reason := ReadBadBlockReason(db, block.Hash()); reason != "" {
+		t.Fatalf("Unexpected bad block reason: %q", reason)
+	}
 
 	// Write the block one again, should be filtered out.
-	WriteBadBlock(db, block)
+	WriteBadBlock(db, block, nil)
 	badBlocks := ReadAllBadBlocks(db)
 	if len(badBlocks) != 2 {
 		t.Fatalf("Failed to load all bad blocks")
@@ -238,7 +245,7 @@ func TestBadBlockStorage(t *testing.T) {
 			TxHash:      types.EmptyRootHash,
 			ReceiptHash: types.EmptyRootHash,
 		})
-		WriteBadBlock(db, block)
+		WriteBadBlock(db, block, nil)
 	}
 	badBlocks = ReadAllBadBlocks(db)
 	if len(badBlocks) != badBlockToKeep {
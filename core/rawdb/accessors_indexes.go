@@ -18,6 +18,7 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -93,6 +94,105 @@ func DeleteTxLookupEntries(db ethdb.KeyValueWriter, hashes []common.Hash) {
 	}
 }
 
+// AddressTxEntry is a positional reference to a transaction either sent from or
+// sent to an indexed address.
+type AddressTxEntry struct {
+	BlockNumber uint64
+	Index       uint32
+	Hash        common.Hash
+}
+
+// ReadAddressTxEntries retrieves at most limit transaction references from the
+// index of the given address, ordered by chain position and starting with the
+// given block number and transaction index (inclusive).
+func ReadAddressTxEntries(db ethdb.Iteratee, address common.Address, number uint64, index uint32, limit int) []AddressTxEntry {
+	prefix := append(append([]byte{}, addressTxPrefix...), address.Bytes()...)
+	start := addressTxKey(address, number, index)[len(prefix):]
+
+	it := db.NewIterator(prefix, start)
+	defer it.Release()
+
+	var entries []AddressTxEntry
+	for it.Next() && len(entries) < limit {
+		key := it.Key()
+		if len(key) != len(prefix)+12 || len(it.Value()) != common.HashLength {
+			continue
+		}
+		entries = append(entries, AddressTxEntry{
+			BlockNumber: binary.BigEndian.Uint64(key[len(prefix):]),
+			Index:       binary.BigEndian.Uint32(key[len(prefix)+8:]),
+			Hash:        common.BytesToHash(it.Value()),
+		})
+	}
+	return entries
+}
+
+// WriteAddressTxEntriesByBlock stores a reference to every transaction from a
+// block in the index of both its sender and its recipient.
+func WriteAddressTxEntriesByBlock(db ethdb.KeyValueWriter, block *types.Block, signer types.Signer) {
+	hashes, participants := blockTxParticipants(block.Transactions(), signer)
+	WriteAddressTxEntries(db, block.NumberU64(), hashes, participants)
+}
+
+// WriteAddressTxEntries stores a reference to every transaction of a block in
+// the index of each of its participants.
+func WriteAddressTxEntries(db ethdb.KeyValueWriter, number uint64, hashes []common.Hash, participants [][]common.Address) {
+	for i, hash := range hashes {
+		for _, address := range participants[i] {
+			if err := db.Put(addressTxKey(address, number, uint32(i)), hash.Bytes()); err != nil {
+				log.Crit("Failed to store address transaction entry", "err", err)
+			}
+		}
+	}
+}
+
+// DeleteAddressTxEntriesByBlock removes the address index references of every
+// transaction from a block.
+func DeleteAddressTxEntriesByBlock(db ethdb.KeyValueWriter, block *types.Block, signer types.Signer) {
+	_, participants := blockTxParticipants(block.Transactions(), signer)
+	DeleteAddressTxEntries(db, block.NumberU64(), participants)
+}
+
+// DeleteAddressTxEntries removes the address index references of every
+// transaction of a block.
+func DeleteAddressTxEntries(db ethdb.KeyValueWriter, number uint64, participants [][]common.Address) {
+	for i, addresses := range participants {
+		for _, address := range addresses {
+			if err := db.Delete(addressTxKey(address, number, uint32(i))); err != nil {
+				log.Crit("Failed to delete address transaction entry", "err", err)
+			}
+		}
+	}
+}
+
+// blockTxParticipants returns the hashes and the participants of a block's
+// transactions.
+func blockTxParticipants(txs types.Transactions, signer types.Signer) ([]common.Hash, [][]common.Address) {
+	var (
+		hashes       = make([]common.Hash, len(txs))
+		participants = make([][]common.Address, len(txs))
+	)
+	for i, tx := range txs {
+		hashes[i], participants[i] = tx.Hash(), addressTxParticipants(tx, signer)
+	}
+	return hashes, participants
+}
+
+// addressTxParticipants returns the deduplicated sender and recipient of a
+// transaction. Contract creations are only indexed for their sender.
+func addressTxParticipants(tx *types.Transaction, signer types.Signer) []common.Address {
+	var addresses []common.Address
+	if from, err := types.Sender(signer, tx); err == nil {
+		addresses = append(addresses, from)
+	} else {
+		log.Error("Failed to derive transaction sender", "hash", tx.Hash(), "err", err)
+	}
+	if to := tx.To(); to != nil && (len(addresses) == 0 || addresses[0] != *to) {
+		addresses = append(addresses, *to)
+	}
+	return addresses
+}
+
 // ReadTransaction retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func ReadTransaction(db ethdb.Reader, hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64) {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -135,6 +136,59 @@ func TestLookupStorage(t *testing.T) {
 	}
 }
 
+// Tests that transactions are indexed by sender and recipient, and that the
+// index can be paged through and cleaned up again.
+func TestAddressTxEntries(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		to1    = common.BytesToAddress([]byte{0x11})
+		to2    = common.BytesToAddress([]byte{0x22})
+		signer = types.HomesteadSigner{}
+	)
+	tx1, _ := types.SignTx(types.NewTransaction(0, to1, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+	tx2, _ := types.SignTx(types.NewTransaction(1, to2, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+	tx3, _ := types.SignTx(types.NewContractCreation(2, big.NewInt(1), 100000, big.NewInt(1), nil), signer, key)
+	tx4, _ := types.SignTx(types.NewTransaction(3, sender, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+
+	block1 := types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{tx1, tx2}, nil, nil, newHasher())
+	block2 := types.NewBlock(&types.Header{Number: big.NewInt(2)}, []*types.Transaction{tx3, tx4}, nil, nil, newHasher())
+	WriteAddressTxEntriesByBlock(db, block1, signer)
+	WriteAddressTxEntriesByBlock(db, block2, signer)
+
+	// Check that every participant sees its own transactions in chain order
+	check := func(address common.Address, number uint64, index uint32, limit int, want []AddressTxEntry) {
+		t.Helper()
+		have := ReadAddressTxEntries(db, address, number, index, limit)
+		if len(have) != len(want) {
+			t.Fatalf("address %x: entry count mismatch: have %d, want %d", address, len(have), len(want))
+		}
+		for i := range have {
+			if have[i] != want[i] {
+				t.Errorf("address %x: entry %d mismatch: have %+v, want %+v", address, i, have[i], want[i])
+			}
+		}
+	}
+	all := []AddressTxEntry{{1, 0, tx1.Hash()}, {1, 1, tx2.Hash()}, {2, 0, tx3.Hash()}, {2, 1, tx4.Hash()}}
+	check(sender, 0, 0, 10, all)
+	check(to1, 0, 0, 10, all[:1])
+	check(to2, 0, 0, 10, all[1:2])
+
+	// Check that the index can be paged through
+	check(sender, 0, 0, 2, all[:2])
+	check(sender, 1, 1, 2, all[1:3])
+	check(sender, 2, 1, 2, all[3:])
+	check(sender, 3, 0, 2, nil)
+
+	// Check that deletion only touches the entries of the given block
+	DeleteAddressTxEntriesByBlock(db, block1, signer)
+	check(sender, 0, 0, 10, all[2:])
+	check(to1, 0, 0, 10, nil)
+	check(to2, 0, 0, 10, nil)
+}
+
 func TestDeleteBloomBits(t *testing.T) {
 	// Prepare testing data
 	db := NewMemoryDatabase()
@@ -39,6 +39,36 @@ func WritePreimages(db ethdb.KeyValueWriter, preimages map[common.Hash][]byte) {
 	preimageHitCounter.Inc(int64(len(preimages)))
 }
 
+// DeletePreimages removes all the stored trie key preimages from the database,
+// returning the number of entries deleted.
+func DeletePreimages(db ethdb.KeyValueStore) (int, error) {
+	it := db.NewIterator(preimagePrefix, nil)
+	defer it.Release()
+
+	var (
+		batch   = db.NewBatch()
+		deleted int
+	)
+	for it.Next() {
+		if key := it.Key(); len(key) == len(preimagePrefix)+common.HashLength {
+			if err := batch.Delete(key); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return deleted, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return deleted, err
+	}
+	return deleted, batch.Write()
+}
+
 // ReadCode retrieves the contract code of the provided code hash.
 func ReadCode(db ethdb.KeyValueReader, hash common.Hash) []byte {
 	// Try with the legacy code scheme first, if not then try with current
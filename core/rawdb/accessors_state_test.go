@@ -0,0 +1,52 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that purging the preimages removes all of them, leaving any unrelated
+// data intact.
+func TestDeletePreimages(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	preimages := make(map[common.Hash][]byte)
+	for i := byte(0); i < 16; i++ {
+		preimages[common.Hash{i}] = []byte{i}
+	}
+	WritePreimages(db, preimages)
+	WriteCode(db, common.Hash{0xff}, []byte{0x01})
+
+	deleted, err := DeletePreimages(db)
+	if err != nil {
+		t.Fatalf("failed to delete preimages: %v", err)
+	}
+	if deleted != len(preimages) {
+		t.Errorf("deleted preimage count mismatch: have %d, want %d", deleted, len(preimages))
+	}
+	for hash := range preimages {
+		if blob := ReadPreimage(db, hash); blob != nil {
+			t.Errorf("preimage %x not deleted", hash)
+		}
+	}
+	if code := ReadCode(db, common.Hash{0xff}); len(code) == 0 {
+		t.Error("unrelated data deleted")
+	}
+}
@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"math/big"
 	"runtime"
 	"sync/atomic"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -79,15 +81,17 @@ func InitDatabaseFromFreezer(db ethdb.Database) {
 }
 
 type blockTxHashes struct {
-	number uint64
-	hashes []common.Hash
+	number       uint64
+	hashes       []common.Hash
+	participants [][]common.Address // Senders and recipients, only derived if requested
 }
 
 // iterateTransactions iterates over all transactions in the (canon) block
-// number(s) given, and yields the hashes on a channel. If there is a signal
-// received from interrupt channel, the iteration will be aborted and result
-// channel will be closed.
-func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, interrupt chan struct{}) chan *blockTxHashes {
+// number(s) given, and yields the hashes on a channel. If a chain config is
+// given, the participants of the transactions are derived too. If there is a
+// signal received from interrupt channel, the iteration will be aborted and
+// result channel will be closed.
+func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, config *params.ChainConfig, interrupt chan struct{}) chan *blockTxHashes {
 	// One thread sequentially reads data from db
 	type numberRlp struct {
 		number uint64
@@ -149,6 +153,10 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 				hashes: hashes,
 				number: data.number,
 			}
+			if config != nil {
+				signer := types.MakeSigner(config, new(big.Int).SetUint64(data.number))
+				_, result.participants = blockTxParticipants(body.Transactions, signer)
+			}
 			// Feed the block to the aggregator, or abort on interrupt
 			select {
 			case hashesCh <- result:
@@ -178,7 +186,7 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 		return
 	}
 	var (
-		hashesCh = iterateTransactions(db, from, to, true, interrupt)
+		hashesCh = iterateTransactions(db, from, to, true, nil, interrupt)
 		batch    = db.NewBatch()
 		start    = time.Now()
 		logged   = start.Add(-7 * time.Second)
@@ -269,7 +277,7 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 		return
 	}
 	var (
-		hashesCh = iterateTransactions(db, from, to, false, interrupt)
+		hashesCh = iterateTransactions(db, from, to, false, nil, interrupt)
 		batch    = db.NewBatch()
 		start    = time.Now()
 		logged   = start.Add(-7 * time.Second)
@@ -345,3 +353,123 @@ func UnindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 func unindexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
 	unindexTransactions(db, from, to, interrupt, hook)
 }
+
+// IndexAddressTransactions creates the address index entries of the specified
+// block range. Like the txlookup indexing, it iterates the canonical chain in
+// reverse order and periodically writes the address index tail, so that an
+// interrupted backfill can be resumed.
+func IndexAddressTransactions(db ethdb.Database, from uint64, to uint64, config *params.ChainConfig, interrupt chan struct{}) {
+	// short circuit for invalid range
+	if from >= to {
+		return
+	}
+	var (
+		hashesCh = iterateTransactions(db, from, to, true, config, interrupt)
+		batch    = db.NewBatch()
+		start    = time.Now()
+		logged   = start.Add(-7 * time.Second)
+		lastNum  = to
+		queue    = prque.New(nil)
+		// for stats reporting
+		blocks, txs = 0, 0
+	)
+	for chanDelivery := range hashesCh {
+		queue.Push(chanDelivery, int64(chanDelivery.number))
+		for !queue.Empty() {
+			// If the next available item is gapped, return
+			if _, priority := queue.Peek(); priority != int64(lastNum-1) {
+				break
+			}
+			delivery := queue.PopItem().(*blockTxHashes)
+			lastNum = delivery.number
+			WriteAddressTxEntries(batch, delivery.number, delivery.hashes, delivery.participants)
+			blocks++
+			txs += len(delivery.hashes)
+			// If enough data was accumulated in memory or we're at the last block, dump to disk
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				WriteAddressTxIndexTail(batch, lastNum) // Also write the tail here
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed writing batch to db", "error", err)
+					return
+				}
+				batch.Reset()
+			}
+			// If we've spent too much time already, notify the user of what we're doing
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Indexing transactions by address", "blocks", blocks, "txs", txs, "tail", lastNum, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+	}
+	WriteAddressTxIndexTail(batch, lastNum)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing batch to db", "error", err)
+		return
+	}
+	select {
+	case <-interrupt:
+		log.Debug("Address transaction indexing interrupted", "blocks", blocks, "txs", txs, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
+	default:
+		log.Info("Indexed transactions by address", "blocks", blocks, "txs", txs, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// UnindexAddressTransactions removes the address index entries of the specified
+// block range, moving the address index tail forward to the end of it.
+func UnindexAddressTransactions(db ethdb.Database, from uint64, to uint64, config *params.ChainConfig, interrupt chan struct{}) {
+	// short circuit for invalid range
+	if from >= to {
+		return
+	}
+	var (
+		hashesCh = iterateTransactions(db, from, to, false, config, interrupt)
+		batch    = db.NewBatch()
+		start    = time.Now()
+		logged   = start.Add(-7 * time.Second)
+		nextNum  = from
+		queue    = prque.New(nil)
+		// for stats reporting
+		blocks, txs = 0, 0
+	)
+	for delivery := range hashesCh {
+		queue.Push(delivery, -int64(delivery.number))
+		for !queue.Empty() {
+			// If the next available item is gapped, return
+			if _, priority := queue.Peek(); -priority != int64(nextNum) {
+				break
+			}
+			delivery := queue.PopItem().(*blockTxHashes)
+			nextNum = delivery.number + 1
+			DeleteAddressTxEntries(batch, delivery.number, delivery.participants)
+			txs += len(delivery.hashes)
+			blocks++
+
+			// A batch counts the size of deletion as '1', so we need to flush more
+			// often than that.
+			if blocks%1000 == 0 {
+				WriteAddressTxIndexTail(batch, nextNum)
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed writing batch to db", "error", err)
+					return
+				}
+				batch.Reset()
+			}
+			// If we've spent too much time already, notify the user of what we're doing
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Unindexing transactions by address", "blocks", blocks, "txs", txs, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+	}
+	WriteAddressTxIndexTail(batch, nextNum)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing batch to db", "error", err)
+		return
+	}
+	select {
+	case <-interrupt:
+		log.Debug("Address transaction unindexing interrupted", "blocks", blocks, "txs", txs, "tail", nextNum, "elapsed", common.PrettyDuration(time.Since(start)))
+	default:
+		log.Info("Unindexed transactions by address", "blocks", blocks, "txs", txs, "tail", nextNum, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
@@ -25,6 +25,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func TestChainIterator(t *testing.T) {
@@ -80,7 +82,7 @@ func TestChainIterator(t *testing.T) {
 	}
 	for i, c := range cases {
 		var numbers []int
-		hashCh := iterateTransactions(chainDb, c.from, c.to, c.reverse, nil)
+		hashCh := iterateTransactions(chainDb, c.from, c.to, c.reverse, nil, nil)
 		if hashCh != nil {
 			for h := range hashCh {
 				numbers = append(numbers, int(h.number))
@@ -206,3 +208,54 @@ func TestIndexTransactions(t *testing.T) {
 	verify(8, 11, true, 8)
 	verify(0, 8, false, 8)
 }
+
+func TestIndexAddressTransactions(t *testing.T) {
+	chainDb := NewMemoryDatabase()
+
+	var (
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		to     = common.BytesToAddress([]byte{0x11})
+		signer = types.LatestSigner(params.TestChainConfig)
+	)
+	// Write empty genesis block
+	block := types.NewBlock(&types.Header{Number: big.NewInt(int64(0))}, nil, nil, nil, newHasher())
+	WriteBlock(chainDb, block)
+	WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+
+	for i := uint64(1); i <= 10; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(i, to, big.NewInt(111), 21000, big.NewInt(11111), nil), signer, key)
+		block = types.NewBlock(&types.Header{Number: big.NewInt(int64(i))}, []*types.Transaction{tx}, nil, nil, newHasher())
+		WriteBlock(chainDb, block)
+		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	}
+	// verify checks that exactly the blocks in [from, 11) are indexed by address
+	verify := func(from uint64) {
+		t.Helper()
+		for _, address := range []common.Address{sender, to} {
+			entries := ReadAddressTxEntries(chainDb, address, 0, 0, 100)
+			if len(entries) != int(11-from) {
+				t.Fatalf("address %x: entry count mismatch: have %d, want %d", address, len(entries), 11-from)
+			}
+			for i, entry := range entries {
+				if entry.BlockNumber != from+uint64(i) {
+					t.Fatalf("address %x: entry %d block mismatch: have %d, want %d", address, i, entry.BlockNumber, from+uint64(i))
+				}
+			}
+		}
+		if tail := ReadAddressTxIndexTail(chainDb); tail == nil || *tail != from {
+			t.Fatalf("address index tail mismatch: have %v, want %d", tail, from)
+		}
+	}
+	IndexAddressTransactions(chainDb, 5, 11, params.TestChainConfig, nil)
+	verify(5)
+
+	IndexAddressTransactions(chainDb, 1, 5, params.TestChainConfig, nil)
+	verify(1)
+
+	UnindexAddressTransactions(chainDb, 1, 8, params.TestChainConfig, nil)
+	verify(8)
+
+	UnindexAddressTransactions(chainDb, 8, 11, params.TestChainConfig, nil)
+	verify(11)
+}
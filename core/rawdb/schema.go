@@ -66,6 +66,9 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// addressTxIndexTailKey tracks the oldest block whose transactions have been indexed by address.
+	addressTxIndexTailKey = []byte("AddressTransactionIndexTail")
+
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	fastTxLookupLimitKey = []byte("FastTransactionLookupLimit")
 
@@ -85,6 +88,7 @@ var (
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
+	addressTxPrefix       = []byte("X") // addressTxPrefix + address + num (uint64 big endian) + index (uint32 big endian) -> transaction hash
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
@@ -182,6 +186,16 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// addressTxKey = addressTxPrefix + address + num (uint64 big endian) + index (uint32 big endian)
+func addressTxKey(address common.Address, number uint64, index uint32) []byte {
+	key := make([]byte, len(addressTxPrefix)+common.AddressLength+12)
+	copy(key, addressTxPrefix)
+	copy(key[len(addressTxPrefix):], address.Bytes())
+	binary.BigEndian.PutUint64(key[len(addressTxPrefix)+common.AddressLength:], number)
+	binary.BigEndian.PutUint32(key[len(addressTxPrefix)+common.AddressLength+8:], index)
+	return key
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
@@ -337,11 +337,19 @@ func (s *stateObject) updateTrie(db Database) Trie {
 	if metrics.EnabledExpensive {
 		defer func(start time.Time) { s.db.StorageUpdates += time.Since(start) }(time.Now())
 	}
-	// The snapshot storage map for the object
-	var storage map[common.Hash][]byte
 	// Insert all the pending updates into the trie
 	tr := s.getTrie(db)
-	hasher := s.db.hasher
+	s.updatePending(tr, s.db.hasher)
+	return tr
+}
+
+// updatePending inserts the pending storage modifications into the given trie.
+// The fields shared with the state database are only accessed while holding its
+// storage lock, so it's safe to call concurrently for distinct objects as long
+// as each one uses its own hasher.
+func (s *stateObject) updatePending(tr Trie, hasher crypto.KeccakState) {
+	// The snapshot storage changes for the object
+	var storage map[common.Hash][]byte
 
 	usedStorage := make([][]byte, 0, len(s.pendingStorage))
 	for key, value := range s.pendingStorage {
@@ -362,36 +370,31 @@ func (s *stateObject) updateTrie(db Database) Trie {
 		// If state snapshotting is active, cache the data til commit
 		if s.db.snap != nil {
 			if storage == nil {
-				// Retrieve the old storage map, if available, create a new one otherwise
-				if storage = s.db.snapStorage[s.addrHash]; storage == nil {
-					storage = make(map[common.Hash][]byte)
-					s.db.snapStorage[s.addrHash] = storage
-				}
+				storage = make(map[common.Hash][]byte)
 			}
 			storage[crypto.HashData(hasher, key[:])] = v // v will be nil if value is 0x00
 		}
 		usedStorage = append(usedStorage, common.CopyBytes(key[:])) // Copy needed for closure
 	}
+	s.db.storageLock.Lock()
+	if storage != nil {
+		// Merge into the old storage map, if available, create a new one otherwise
+		if old := s.db.snapStorage[s.addrHash]; old != nil {
+			for hash, v := range storage {
+				old[hash] = v
+			}
+		} else {
+			s.db.snapStorage[s.addrHash] = storage
+		}
+	}
 	if s.db.prefetcher != nil {
 		s.db.prefetcher.used(s.data.Root, usedStorage)
 	}
+	s.db.storageLock.Unlock()
+
 	if len(s.pendingStorage) > 0 {
 		s.pendingStorage = make(Storage)
 	}
-	return tr
-}
-
-// UpdateRoot sets the trie root to the current root hash of
-func (s *stateObject) updateRoot(db Database) {
-	// If nothing changed, don't bother with hashing anything
-	if s.updateTrie(db) == nil {
-		return
-	}
-	// Track the amount of time wasted on hashing the storage trie
-	if metrics.EnabledExpensive {
-		defer func(start time.Time) { s.db.StorageHashes += time.Since(start) }(time.Now())
-	}
-	s.data.Root = s.trie.Hash()
 }
 
 // CommitTrie the storage trie of the object to db.
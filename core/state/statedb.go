@@ -21,7 +21,10 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"runtime"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -73,6 +76,7 @@ type StateDB struct {
 	snapDestructs map[common.Hash]struct{}
 	snapAccounts  map[common.Hash][]byte
 	snapStorage   map[common.Hash]map[common.Hash][]byte
+	storageLock   sync.Mutex // Guards the snapshot storage and prefetcher during concurrent storage updates
 
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects        map[common.Address]*stateObject
@@ -843,11 +847,13 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// the account prefetcher. Instead, let's process all the storage updates
 	// first, giving the account prefeches just a few more milliseconds of time
 	// to pull useful data from disk.
+	objs := make([]*stateObject, 0, len(s.stateObjectsPending))
 	for addr := range s.stateObjectsPending {
 		if obj := s.stateObjects[addr]; !obj.deleted {
-			obj.updateRoot(s.db)
+			objs = append(objs, obj)
 		}
 	}
+	s.updateStorageRoots(objs)
 	// Now we're about to start to write changes to the trie. The trie is so far
 	// _untouched_. We can check with the prefetcher, if it can give us a trie
 	// which has the same root, but also has some content loaded into it.
@@ -894,6 +900,65 @@ func (s *StateDB) clearJournalAndRefund() {
 	s.validRevisions = s.validRevisions[:0] // Snapshots can be created without journal entires
 }
 
+// updateStorageRoots writes the pending storage changes of the given objects into
+// their storage tries and recalculates the roots. Since the storage tries are
+// independent of each other, both steps are spread across multiple goroutines.
+func (s *StateDB) updateStorageRoots(objs []*stateObject) {
+	// Open the tries of all the updated objects upfront, as retrieving them from
+	// the prefetcher cannot be done concurrently
+	var start time.Time
+	if metrics.EnabledExpensive {
+		start = time.Now()
+	}
+	updated := objs[:0]
+	for _, obj := range objs {
+		obj.finalise(false)
+		if len(obj.pendingStorage) > 0 {
+			obj.getTrie(s.db)
+			updated = append(updated, obj)
+		}
+	}
+	// Insert the changes into the tries, then rehash them
+	parallelize(len(updated), func(i int, hasher crypto.KeccakState) {
+		updated[i].updatePending(updated[i].trie, hasher)
+	})
+	if metrics.EnabledExpensive {
+		s.StorageUpdates += time.Since(start)
+		start = time.Now()
+	}
+	parallelize(len(updated), func(i int, hasher crypto.KeccakState) {
+		updated[i].data.Root = updated[i].trie.Hash()
+	})
+	if metrics.EnabledExpensive {
+		s.StorageHashes += time.Since(start)
+	}
+}
+
+// parallelize runs the given task for all indices in [0, n), distributing them
+// across a number of worker goroutines, each with its own keccak hasher.
+func parallelize(n int, task func(i int, hasher crypto.KeccakState)) {
+	workers := runtime.NumCPU()
+	if workers > n {
+		workers = n
+	}
+	var (
+		next int32 = -1
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			hasher := crypto.NewKeccakState()
+			for i := int(atomic.AddInt32(&next, 1)); i < n; i = int(atomic.AddInt32(&next, 1)) {
+				task(i, hasher)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 // Commit writes the state to the underlying in-memory trie database.
 func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 	if s.dbErr != nil {
@@ -915,3 +915,29 @@ func TestStateDBAccessList(t *testing.T) {
 		t.Fatalf("expected empty, got %d", got)
 	}
 }
+
+// Tests that the storage roots computed concurrently for a batch of accounts
+// match the ones computed one account at a time.
+func TestConcurrentStorageRoots(t *testing.T) {
+	var (
+		batch, _  = New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		serial, _ = New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	)
+	for i := byte(0); i < 128; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		for j := byte(0); j <= i%16; j++ {
+			batch.SetState(addr, common.Hash{j}, common.Hash{i, j})
+			serial.SetState(addr, common.Hash{j}, common.Hash{i, j})
+		}
+		serial.IntermediateRoot(false)
+	}
+	if have, want := batch.IntermediateRoot(false), serial.IntermediateRoot(false); have != want {
+		t.Fatalf("state root mismatch: have %x, want %x", have, want)
+	}
+	for i := byte(0); i < 128; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		if have, want := batch.getStateObject(addr).data.Root, serial.getStateObject(addr).data.Root; have != want {
+			t.Errorf("account %d: storage root mismatch: have %x, want %x", i, have, want)
+		}
+	}
+}
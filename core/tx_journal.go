@@ -45,6 +45,8 @@ func (*devNull) Close() error                      { return nil }
 type txJournal struct {
 	path   string         // Filesystem path to store the transactions at
 	writer io.WriteCloser // Output stream to write new transactions into
+	size   uint64         // Current size of the journal in bytes
+	base   uint64         // Size of the journal after the last regeneration
 }
 
 // newTxJournal creates a new transaction journal to
@@ -121,12 +123,25 @@ func (journal *txJournal) insert(tx *types.Transaction) error {
 	if journal.writer == nil {
 		return errNoActiveJournal
 	}
-	if err := rlp.Encode(journal.writer, tx); err != nil {
+	blob, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return err
+	}
+	if _, err := journal.writer.Write(blob); err != nil {
 		return err
 	}
+	journal.size += uint64(len(blob))
 	return nil
 }
 
+// oversized reports whether the journal grew beyond the given size limit and
+// should be regenerated. To avoid regenerating it on every insertion when the
+// live set itself exceeds the limit, the journal must have also doubled in size
+// since its last regeneration.
+func (journal *txJournal) oversized(limit uint64) bool {
+	return limit > 0 && journal.size > limit && journal.size > 2*journal.base
+}
+
 // rotate regenerates the transaction journal based on the current contents of
 // the transaction pool.
 func (journal *txJournal) rotate(all map[common.Address]types.Transactions) error {
@@ -142,13 +157,21 @@ func (journal *txJournal) rotate(all map[common.Address]types.Transactions) erro
 	if err != nil {
 		return err
 	}
-	journaled := 0
+	var (
+		journaled int
+		size      uint64
+	)
 	for _, txs := range all {
 		for _, tx := range txs {
-			if err = rlp.Encode(replacement, tx); err != nil {
+			blob, err := rlp.EncodeToBytes(tx)
+			if err == nil {
+				_, err = replacement.Write(blob)
+			}
+			if err != nil {
 				replacement.Close()
 				return err
 			}
+			size += uint64(len(blob))
 		}
 		journaled += len(txs)
 	}
@@ -163,7 +186,8 @@ func (journal *txJournal) rotate(all map[common.Address]types.Transactions) erro
 		return err
 	}
 	journal.writer = sink
-	log.Info("Regenerated local transaction journal", "transactions", journaled, "accounts", len(all))
+	journal.size, journal.base = size, size
+	log.Info("Regenerated local transaction journal", "transactions", journaled, "accounts", len(all), "size", common.StorageSize(size))
 
 	return nil
 }
@@ -51,6 +51,26 @@ func TestStrictTxListAdd(t *testing.T) {
 	}
 }
 
+// Tests that without a price bump, transactions can be replaced by equally priced
+// ones, but still not by cheaper ones.
+func TestTxListReplaceZeroPriceBump(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	list := newTxList(true)
+	if inserted, _ := list.Add(pricedTransaction(0, 100000, big.NewInt(10), key), 0); !inserted {
+		t.Fatalf("failed to insert original transaction")
+	}
+	if inserted, _ := list.Add(pricedTransaction(0, 100000, big.NewInt(9), key), 0); inserted {
+		t.Errorf("cheaper replacement accepted")
+	}
+	if inserted, old := list.Add(pricedTransaction(0, 200000, big.NewInt(10), key), 0); !inserted || old == nil {
+		t.Errorf("equally priced replacement rejected")
+	}
+	if inserted, _ := list.Add(pricedTransaction(0, 300000, big.NewInt(10), key), DefaultTxPoolConfig.PriceBump); inserted {
+		t.Errorf("equally priced replacement accepted with price bump")
+	}
+}
+
 func BenchmarkTxListAdd(t *testing.B) {
 	// Generate a list of transactions to insert
 	key, _ := crypto.GenerateKey()
@@ -97,6 +97,7 @@ var (
 	pendingReplaceMeter   = metrics.NewRegisteredMeter("txpool/pending/replace", nil)
 	pendingRateLimitMeter = metrics.NewRegisteredMeter("txpool/pending/ratelimit", nil) // Dropped due to rate limiting
 	pendingNofundsMeter   = metrics.NewRegisteredMeter("txpool/pending/nofunds", nil)   // Dropped due to out-of-funds
+	pendingEvictionMeter  = metrics.NewRegisteredMeter("txpool/pending/eviction", nil)  // Dropped due to lifetime
 
 	// Metrics for the queued pool
 	queuedDiscardMeter   = metrics.NewRegisteredMeter("txpool/queued/discard", nil)
@@ -142,20 +143,22 @@ type blockChain interface {
 
 // TxPoolConfig are the configuration parameters of the transaction pool.
 type TxPoolConfig struct {
-	Locals    []common.Address // Addresses that should be treated by default as local
-	NoLocals  bool             // Whether local transaction handling should be disabled
-	Journal   string           // Journal of local transactions to survive node restarts
-	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+	Locals       []common.Address // Addresses that should be treated by default as local
+	NoLocals     bool             // Whether local transaction handling should be disabled
+	Journal      string           // Journal of local transactions to survive node restarts
+	Rejournal    time.Duration    // Time interval to regenerate the local transaction journal
+	JournalLimit uint64           // Journal size in bytes above which it's regenerated early (0 = unlimited)
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
-	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
+	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce), 0 = last write wins
 
 	AccountSlots uint64 // Number of executable transaction slots guaranteed per account
 	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
-	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+	Lifetime        time.Duration // Maximum amount of time non-executable transaction are queued
+	PendingLifetime time.Duration // Maximum amount of time executable transactions are pending (0 = unlimited)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -187,10 +190,6 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultTxPoolConfig.PriceLimit)
 		conf.PriceLimit = DefaultTxPoolConfig.PriceLimit
 	}
-	if conf.PriceBump < 1 {
-		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
-		conf.PriceBump = DefaultTxPoolConfig.PriceBump
-	}
 	if conf.AccountSlots < 1 {
 		log.Warn("Sanitizing invalid txpool account slots", "provided", conf.AccountSlots, "updated", DefaultTxPoolConfig.AccountSlots)
 		conf.AccountSlots = DefaultTxPoolConfig.AccountSlots
@@ -227,6 +226,7 @@ type TxPool struct {
 	chain       blockChain
 	gasPrice    *big.Int
 	txFeed      event.Feed
+	staleFeed   event.Feed
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
@@ -252,6 +252,7 @@ type TxPool struct {
 	chainHeadSub    event.Subscription
 	reqResetCh      chan *txpoolResetRequest
 	reqPromoteCh    chan *accountSet
+	reqRejournalCh  chan struct{}
 	queueTxEventCh  chan *types.Transaction
 	reorgDoneCh     chan chan struct{}
 	reorgShutdownCh chan struct{}  // requests shutdown of scheduleReorgLoop
@@ -281,6 +282,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		chainHeadCh:     make(chan ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
+		reqRejournalCh:  make(chan struct{}, 1),
 		queueTxEventCh:  make(chan *types.Transaction),
 		reorgDoneCh:     make(chan chan struct{}),
 		reorgShutdownCh: make(chan struct{}),
@@ -365,6 +367,8 @@ func (pool *TxPool) loop() {
 
 		// Handle inactive account transaction eviction
 		case <-evict.C:
+			var stales types.Transactions
+
 			pool.mu.Lock()
 			for addr := range pool.queue {
 				// Skip local transactions from the eviction mechanism
@@ -378,10 +382,18 @@ func (pool *TxPool) loop() {
 						pool.removeTx(tx.Hash(), true)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
+					stales = append(stales, list...)
 				}
 			}
+			if pool.config.PendingLifetime > 0 {
+				stales = append(stales, pool.evictPending()...)
+			}
 			pool.mu.Unlock()
 
+			if len(stales) > 0 {
+				pool.staleFeed.Send(StaleTxsEvent{stales})
+			}
+
 		// Handle local transaction journal rotation
 		case <-journal.C:
 			if pool.journal != nil {
@@ -391,6 +403,18 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+
+		// Handle early journal rotation if it grew too large
+		case <-pool.reqRejournalCh:
+			if pool.journal != nil {
+				pool.mu.Lock()
+				if pool.journal.oversized(pool.config.JournalLimit) {
+					if err := pool.journal.rotate(pool.local()); err != nil {
+						log.Warn("Failed to rotate oversized local tx journal", "err", err)
+					}
+				}
+				pool.mu.Unlock()
+			}
 		}
 	}
 }
@@ -416,6 +440,12 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeStaleTxsEvent registers a subscription of StaleTxsEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeStaleTxsEvent(ch chan<- StaleTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.staleFeed.Subscribe(ch))
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -445,6 +475,76 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// SlotQuotas returns the per-account and global slot quotas currently enforced
+// on the executable and non-executable transactions of the pool.
+func (pool *TxPool) SlotQuotas() (accountSlots, globalSlots, accountQueue, globalQueue uint64) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.config.AccountSlots, pool.config.GlobalSlots, pool.config.AccountQueue, pool.config.GlobalQueue
+}
+
+// SetSlotQuotas updates the per-account and global slot quotas of the pool and
+// waits until the new limits are enforced on the already pooled transactions.
+func (pool *TxPool) SetSlotQuotas(accountSlots, globalSlots, accountQueue, globalQueue uint64) error {
+	if accountSlots == 0 || globalSlots == 0 || accountQueue == 0 || globalQueue == 0 {
+		return errors.New("slot quotas must be positive")
+	}
+	pool.mu.Lock()
+	pool.config.AccountSlots, pool.config.GlobalSlots = accountSlots, globalSlots
+	pool.config.AccountQueue, pool.config.GlobalQueue = accountQueue, globalQueue
+
+	// Recheck all the queued accounts against their new caps
+	dirty := newAccountSet(pool.signer)
+	for addr := range pool.queue {
+		dirty.add(addr)
+	}
+	pool.mu.Unlock()
+
+	log.Info("Transaction pool slot quotas updated", "accountslots", accountSlots, "globalslots", globalSlots, "accountqueue", accountQueue, "globalqueue", globalQueue)
+	<-pool.requestPromoteExecutables(dirty)
+	return nil
+}
+
+// RemoveTx drops a transaction from the pool along with all the higher nonce
+// transactions of the same sender depending on it, returning the dropped ones.
+// Dropped local transactions are removed from the journal too.
+func (pool *TxPool) RemoveTx(hash common.Hash) types.Transactions {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	tx := pool.all.Get(hash)
+	if tx == nil {
+		return nil
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated during insertion
+
+	var drops types.Transactions
+	for _, list := range []*txList{pool.pending[from], pool.queue[from]} {
+		if list == nil {
+			continue
+		}
+		for _, dep := range list.Flatten() {
+			if dep.Nonce() >= tx.Nonce() {
+				drops = append(drops, dep)
+			}
+		}
+	}
+	for _, tx := range drops {
+		pool.removeTx(tx.Hash(), true)
+	}
+	log.Info("Removed transactions from the pool", "hash", hash, "from", from, "count", len(drops))
+
+	// Regenerate the journal, otherwise local transactions would be reinjected
+	// on the next restart
+	if pool.journal != nil && pool.locals.contains(from) {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+	}
+	return drops
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (pool *TxPool) Nonce(addr common.Address) uint64 {
@@ -759,6 +859,24 @@ func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
 	if err := pool.journal.insert(tx); err != nil {
 		log.Warn("Failed to journal local transaction", "err", err)
 	}
+	if pool.journal.oversized(pool.config.JournalLimit) {
+		select {
+		case pool.reqRejournalCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// CompactJournal regenerates the local transaction journal from the current
+// contents of the pool, dropping all the included and invalidated entries.
+func (pool *TxPool) CompactJournal() error {
+	if pool.journal == nil {
+		return errNoActiveJournal
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.journal.rotate(pool.local())
 }
 
 // promoteTx adds a transaction to the pending (processable) list of transactions
@@ -943,6 +1061,33 @@ func (pool *TxPool) Has(hash common.Hash) bool {
 	return pool.all.Get(hash) != nil
 }
 
+// evictPending drops the non-local executable transactions pending for longer
+// than the configured lifetime, along with all their dependents, which would
+// otherwise be stuck behind them.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) evictPending() types.Transactions {
+	var stales types.Transactions
+	for addr, list := range pool.pending {
+		// Skip local transactions from the eviction mechanism
+		if pool.locals.contains(addr) {
+			continue
+		}
+		txs := list.Flatten()
+		for i, tx := range txs {
+			if time.Since(tx.Time()) > pool.config.PendingLifetime {
+				for _, tx := range txs[i:] {
+					pool.removeTx(tx.Hash(), true)
+				}
+				pendingEvictionMeter.Mark(int64(len(txs) - i))
+				stales = append(stales, txs[i:]...)
+				break
+			}
+		}
+	}
+	return stales
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
@@ -20,10 +20,12 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -34,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 )
 
@@ -938,6 +941,132 @@ func testTransactionQueueGlobalLimiting(t *testing.T, nolocals bool) {
 	}
 }
 
+// Tests that removing a transaction from the pool also drops all the subsequent
+// transactions of the same sender, both pending and queued.
+func TestTransactionRemoval(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000))
+
+	txs := types.Transactions{
+		transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key),
+		transaction(3, 100000, key), transaction(5, 100000, key), transaction(6, 100000, key),
+	}
+	pool.AddRemotesSync(txs)
+
+	if pending, queued := pool.Stats(); pending != 4 || queued != 2 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 4, 2)
+	}
+	drops := pool.RemoveTx(txs[2].Hash())
+	if len(drops) != 4 {
+		t.Fatalf("dropped transaction count mismatch: have %d, want %d", len(drops), 4)
+	}
+	for i, tx := range drops {
+		if tx.Hash() != txs[i+2].Hash() {
+			t.Errorf("dropped transaction %d mismatch: have %x, want %x", i, tx.Hash(), txs[i+2].Hash())
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 2, 0)
+	}
+	if nonce := pool.Nonce(account); nonce != 2 {
+		t.Errorf("pending nonce mismatch: have %d, want %d", nonce, 2)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	if drops := pool.RemoveTx(txs[2].Hash()); len(drops) != 0 {
+		t.Errorf("removed unknown transaction: %v", drops)
+	}
+}
+
+// Tests that removed local transactions are dropped from the journal too, so
+// they are not reinjected after a restart.
+func TestTransactionRemovalJournal(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Journal = filepath.Join(dir, "transactions.rlp")
+
+	key, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		if err := pool.AddLocal(transaction(nonce, 100000, key)); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", nonce, err)
+		}
+	}
+	if drops := pool.RemoveTx(transaction(1, 100000, key).Hash()); len(drops) != 2 {
+		t.Fatalf("dropped transaction count mismatch: have %d, want %d", len(drops), 2)
+	}
+	pool.Stop()
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("pool stats mismatch after restart: have %d/%d, want %d/%d", pending, queued, 1, 0)
+	}
+}
+
+// Tests that lowering the slot quotas at runtime drops the pooled transactions
+// exceeding the new limits.
+func TestTransactionSlotQuotaUpdate(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	// Fill the pool with two accounts of executables and one of gapped transactions
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	var txs types.Transactions
+	for i := uint64(0); i < 8; i++ {
+		txs = append(txs, transaction(i, 100000, keys[0]), transaction(i, 100000, keys[1]), transaction(i+1, 100000, keys[2]))
+	}
+	pool.AddRemotesSync(txs)
+
+	if pending, queued := pool.Stats(); pending != 16 || queued != 8 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 16, 8)
+	}
+	// Lower the quotas and ensure the surplus transactions are dropped
+	if err := pool.SetSlotQuotas(4, 8, 4, testTxPoolConfig.GlobalQueue); err != nil {
+		t.Fatalf("failed to update slot quotas: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 8 || queued != 4 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 8, 4)
+	}
+	if accountSlots, globalSlots, accountQueue, _ := pool.SlotQuotas(); accountSlots != 4 || globalSlots != 8 || accountQueue != 4 {
+		t.Errorf("slot quotas mismatch: have %d/%d/%d, want %d/%d/%d", accountSlots, globalSlots, accountQueue, 4, 8, 4)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	if err := pool.SetSlotQuotas(0, 8, 4, 4); err == nil {
+		t.Error("zero slot quota accepted")
+	}
+}
+
 // Tests that if an account remains idle for a prolonged amount of time, any
 // non-executable transactions queued up are dropped to prevent wasting resources
 // on shuffling them around.
@@ -1094,6 +1223,66 @@ func testTransactionQueueTimeLimiting(t *testing.T, nolocals bool) {
 	}
 }
 
+// Tests that executable transactions pending for longer than the configured
+// lifetime are dropped along with their dependents and announced as stale, but
+// local ones are kept.
+func TestTransactionPendingTimeLimiting(t *testing.T) {
+	// Reduce the eviction interval to a testable amount
+	defer func(old time.Duration) { evictionInterval = old }(evictionInterval)
+	evictionInterval = time.Millisecond * 100
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.PendingLifetime = time.Second
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	stales := make(chan StaleTxsEvent, 1)
+	sub := pool.SubscribeStaleTxsEvent(stales)
+	defer sub.Unsubscribe()
+
+	// Create two test accounts to ensure remotes expire but locals do not
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	for i := uint64(0); i < 2; i++ {
+		if err := pool.AddRemotesSync([]*types.Transaction{pricedTransaction(i, 100000, big.NewInt(1), remote)})[0]; err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	// Wait for the remote transactions to expire and be announced
+	select {
+	case ev := <-stales:
+		if len(ev.Txs) != 2 {
+			t.Fatalf("stale transactions mismatched: have %d, want %d", len(ev.Txs), 2)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for stale transactions")
+	}
+	pending, queued := pool.Stats()
+	if pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that even if the transaction count belonging to a single account goes
 // above some threshold, as long as the transactions are executable, they are
 // accepted.
@@ -2348,6 +2537,95 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that the local transaction journal can be compacted on demand and is
+// regenerated early when growing beyond its size limit.
+func TestTransactionJournalCompaction(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	file.Close()
+	os.Remove(journal)
+
+	// Create a pool with a tiny journal size limit
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Journal = journal
+	config.JournalLimit = 1
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000000))
+
+	// journaled retrieves the nonces of the transactions in the journal file
+	journaled := func() []uint64 {
+		input, err := os.Open(journal)
+		if err != nil {
+			t.Fatalf("failed to open journal: %v", err)
+		}
+		defer input.Close()
+
+		var nonces []uint64
+		for stream := rlp.NewStream(input, 0); ; {
+			tx := new(types.Transaction)
+			if err := stream.Decode(tx); err == io.EOF {
+				return nonces
+			} else if err != nil {
+				t.Fatalf("failed to decode journal: %v", err)
+			}
+			nonces = append(nonces, tx.Nonce())
+		}
+	}
+	for i := uint64(0); i < 3; i++ {
+		if err := pool.AddLocal(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", i, err)
+		}
+	}
+	// Include the first two transactions and ensure compaction drops them
+	statedb.SetNonce(account, 2)
+	<-pool.requestReset(nil, nil)
+
+	if nonces := journaled(); len(nonces) != 3 {
+		t.Fatalf("journaled transactions mismatch before compaction: have %v, want 3 entries", nonces)
+	}
+	if err := pool.CompactJournal(); err != nil {
+		t.Fatalf("failed to compact journal: %v", err)
+	}
+	if nonces := journaled(); len(nonces) != 1 || nonces[0] != 2 {
+		t.Fatalf("journaled transactions mismatch after compaction: have %v, want [2]", nonces)
+	}
+	// Include the last transaction and ensure growing the journal drops it
+	statedb.SetNonce(account, 3)
+	<-pool.requestReset(nil, nil)
+
+	for i := uint64(3); i < 5; i++ {
+		if err := pool.AddLocal(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", i, err)
+		}
+	}
+	for i := 0; ; i++ {
+		nonces := journaled()
+		if len(nonces) == 2 && nonces[0] == 3 && nonces[1] == 4 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("journaled transactions mismatch after growth: have %v, want [3 4]", nonces)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
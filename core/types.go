@@ -29,6 +29,10 @@ type Validator interface {
 	// ValidateBody validates the given block's content.
 	ValidateBody(block *types.Block) error
 
+	// ValidateBodyConcurrently validates the given block's linkage to the local
+	// chain, starting the validation of its content in the background.
+	ValidateBodyConcurrently(block *types.Block) (<-chan error, error)
+
 	// ValidateState validates the given statedb and optionally the receipts and
 	// gas used.
 	ValidateState(block *types.Block, state *state.StateDB, receipts types.Receipts, usedGas uint64) error
@@ -16,6 +16,21 @@
 
 package vm
 
+import (
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// analysisCacheSize is the number of code hash -> JUMPDEST analysis associations
+// to keep across transactions. With the contract size capped at 24KB, a single
+// bitmap is at most ~3KB, bounding the cache to a few megabytes.
+const analysisCacheSize = 4096
+
+// analysisCache holds the JUMPDEST analysis of recently executed contracts, so
+// that hot contracts are not re-analysed in every transaction and block. The
+// cached bitmaps are never mutated, so they can be shared across EVM instances.
+var analysisCache, _ = lru.New(analysisCacheSize)
+
 // bitvec is a bit vector which maps bytes in a program.
 // An unset bit means the byte is an opcode, a set bit means
 // it's data (i.e. argument of PUSHxx).
@@ -60,3 +75,14 @@ func codeBitmap(code []byte) bitvec {
 	}
 	return bits
 }
+
+// codeBitmapCached returns the JUMPDEST analysis of the code with the given hash,
+// reusing a cached result if available or analysing and caching it otherwise.
+func codeBitmapCached(hash common.Hash, code []byte) bitvec {
+	if cached, ok := analysisCache.Get(hash); ok {
+		return cached.(bitvec)
+	}
+	analysis := codeBitmap(code)
+	analysisCache.Add(hash, analysis)
+	return analysis
+}
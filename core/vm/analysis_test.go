@@ -17,9 +17,12 @@
 package vm
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
 )
 
 func TestJumpDestAnalysis(t *testing.T) {
@@ -55,6 +58,40 @@ func TestJumpDestAnalysis(t *testing.T) {
 	}
 }
 
+// Tests that the JUMPDEST analysis of contracts is shared across independent
+// executions of the same code.
+func TestJumpDestAnalysisCache(t *testing.T) {
+	code := []byte{byte(PUSH1), byte(JUMPDEST), byte(JUMPDEST)}
+	hash := crypto.Keccak256Hash(code)
+
+	analysisCache.Remove(hash)
+	for i := 0; i < 2; i++ {
+		contract := NewContract(AccountRef(common.Address{}), AccountRef(common.Address{0x01}), new(big.Int), 0)
+		contract.SetCallCode(&common.Address{0x01}, hash, code)
+
+		if dest := uint256.NewInt(1); contract.validJumpdest(dest) {
+			t.Errorf("run %d: jump into push data accepted", i)
+		}
+		if dest := uint256.NewInt(2); !contract.validJumpdest(dest) {
+			t.Errorf("run %d: valid jump destination rejected", i)
+		}
+		cached, ok := analysisCache.Get(hash)
+		if !ok {
+			t.Fatalf("run %d: analysis not cached", i)
+		}
+		if &cached.(bitvec)[0] != &contract.analysis[0] {
+			t.Errorf("run %d: cached analysis not reused", i)
+		}
+	}
+	// Code without a hash (initcode) must not pollute the cache
+	contract := NewContract(AccountRef(common.Address{}), AccountRef(common.Address{0x01}), new(big.Int), 0)
+	contract.SetCallCode(&common.Address{0x01}, common.Hash{}, code)
+	contract.validJumpdest(uint256.NewInt(2))
+	if analysisCache.Contains(common.Hash{}) {
+		t.Errorf("analysis of unhashed code cached")
+	}
+}
+
 func BenchmarkJumpdestAnalysis_1200k(bench *testing.B) {
 	// 1.4 ms
 	code := make([]byte, 1200000)
@@ -73,3 +110,13 @@ func BenchmarkJumpdestHashing_1200k(bench *testing.B) {
 	}
 	bench.StopTimer()
 }
+
+func BenchmarkJumpdestCachedAnalysis_24k(bench *testing.B) {
+	code := make([]byte, 24576)
+	hash := crypto.Keccak256Hash(code)
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		codeBitmapCached(hash, code)
+	}
+	bench.StopTimer()
+}
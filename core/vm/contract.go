@@ -110,9 +110,9 @@ func (c *Contract) isCode(udest uint64) bool {
 		// Does parent context have the analysis?
 		analysis, exist := c.jumpdests[c.CodeHash]
 		if !exist {
-			// Do the analysis and save in parent context
-			// We do not need to store it in c.analysis
-			analysis = codeBitmap(c.Code)
+			// Retrieve the analysis from the shared cache or do it, and save
+			// in parent context. We do not need to store it in c.analysis
+			analysis = codeBitmapCached(c.CodeHash, c.Code)
 			c.jumpdests[c.CodeHash] = analysis
 		}
 		// Also stash it in current contract for faster access
@@ -130,16 +130,22 @@ func init() {
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	var addrs []common.Address
 	switch {
 	case rules.IsBerlin:
-		return PrecompiledAddressesBerlin
+		addrs = PrecompiledAddressesBerlin
 	case rules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		addrs = PrecompiledAddressesIstanbul
 	case rules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		addrs = PrecompiledAddressesByzantium
 	default:
-		return PrecompiledAddressesHomestead
+		addrs = PrecompiledAddressesHomestead
 	}
+	addrs = activeRegisteredPrecompiles(addrs, rules)
+	if len(rules.CustomPrecompiles) == 0 {
+		return addrs
+	}
+	return append(append([]common.Address{}, addrs...), rules.CustomPrecompiles...)
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
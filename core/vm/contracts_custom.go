@@ -0,0 +1,200 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// The precompile registries are populated from init functions and are frozen
+// once the first EVM is created. From then on they are only ever read, so the
+// EVM consults them on every call without any locking. Registering afterwards
+// panics.
+var (
+	namedPrecompiles      = make(map[string]PrecompiledContract)
+	registeredPrecompiles = make(map[common.Address]*registeredPrecompile)
+
+	precompilesFrozen uint32 // Set once the registries may no longer change
+
+	// activeRegisteredCache caches the sorted active precompile addresses (built
+	// in and registered ones) per combination of forks, keyed by forkMask.
+	activeRegisteredCache sync.Map
+)
+
+// registeredPrecompile is a precompiled contract added to the EVM from outside
+// of the built-in sets, along with the rules deciding when it's active.
+type registeredPrecompile struct {
+	contract PrecompiledContract
+	active   func(rules params.Rules) bool // nil means always active
+}
+
+func init() {
+	// Make the EIP-2537 contracts available to private networks
+	RegisterNamedPrecompile("bls12381G1Add", &bls12381G1Add{})
+	RegisterNamedPrecompile("bls12381G1Mul", &bls12381G1Mul{})
+	RegisterNamedPrecompile("bls12381G1MultiExp", &bls12381G1MultiExp{})
+	RegisterNamedPrecompile("bls12381G2Add", &bls12381G2Add{})
+	RegisterNamedPrecompile("bls12381G2Mul", &bls12381G2Mul{})
+	RegisterNamedPrecompile("bls12381G2MultiExp", &bls12381G2MultiExp{})
+	RegisterNamedPrecompile("bls12381Pairing", &bls12381Pairing{})
+	RegisterNamedPrecompile("bls12381MapG1", &bls12381MapG1{})
+	RegisterNamedPrecompile("bls12381MapG2", &bls12381MapG2{})
+}
+
+// freezePrecompiles marks the precompile registries read only.
+func freezePrecompiles() {
+	if atomic.LoadUint32(&precompilesFrozen) == 0 {
+		atomic.StoreUint32(&precompilesFrozen, 1)
+	}
+}
+
+// checkPrecompilesWritable panics if the precompile registries are frozen.
+func checkPrecompilesWritable(what string) {
+	if atomic.LoadUint32(&precompilesFrozen) == 1 {
+		panic(fmt.Sprintf("%s registered after EVM creation", what))
+	}
+}
+
+// RegisterPrecompile adds a precompiled contract at the given address, which the
+// EVM consults alongside the built-in sets whenever the activation rules report
+// it active (a nil activation function means always). The activation function
+// must only depend on the fork flags of the rules, as its results are cached per
+// combination of forks.
+//
+// RegisterPrecompile must be called from init functions: it is not safe for
+// concurrent use, and panics once an EVM was created or if the address is
+// already taken.
+func RegisterPrecompile(addr common.Address, contract PrecompiledContract, active func(rules params.Rules) bool) {
+	checkPrecompilesWritable(fmt.Sprintf("precompile %x", addr))
+
+	if _, ok := PrecompiledContractsBerlin[addr]; ok {
+		panic(fmt.Sprintf("precompile %x: address reserved for built-in contract", addr))
+	}
+	if _, ok := registeredPrecompiles[addr]; ok {
+		panic(fmt.Sprintf("precompile %x already registered", addr))
+	}
+	registeredPrecompiles[addr] = &registeredPrecompile{contract: contract, active: active}
+}
+
+// registeredPrecompileAt retrieves the registered precompiled contract at the
+// given address, if one is active under the given chain rules.
+func registeredPrecompileAt(addr common.Address, rules params.Rules) (PrecompiledContract, bool) {
+	p, ok := registeredPrecompiles[addr]
+	if !ok || (p.active != nil && !p.active(rules)) {
+		return nil, false
+	}
+	return p.contract, true
+}
+
+// forkMask packs the fork flags of the chain rules into a cache key.
+func forkMask(rules params.Rules) uint16 {
+	var mask uint16
+	for i, active := range []bool{
+		rules.IsHomestead, rules.IsEIP150, rules.IsEIP155, rules.IsEIP158,
+		rules.IsByzantium, rules.IsConstantinople, rules.IsPetersburg, rules.IsIstanbul,
+		rules.IsBerlin, rules.IsLondon, rules.IsCatalyst,
+	} {
+		if active {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+// activeRegisteredPrecompiles returns the given built-in precompile addresses
+// extended with the registered ones active under the chain rules, sorted by
+// address. The result is cached per combination of forks and must not be
+// modified.
+func activeRegisteredPrecompiles(builtins []common.Address, rules params.Rules) []common.Address {
+	if len(registeredPrecompiles) == 0 {
+		return builtins
+	}
+	freezePrecompiles()
+
+	key := forkMask(rules)
+	if addrs, ok := activeRegisteredCache.Load(key); ok {
+		return addrs.([]common.Address)
+	}
+	var registered []common.Address
+	for addr, p := range registeredPrecompiles {
+		if p.active == nil || p.active(rules) {
+			registered = append(registered, addr)
+		}
+	}
+	sort.Slice(registered, func(i, j int) bool { return bytes.Compare(registered[i][:], registered[j][:]) < 0 })
+
+	addrs := append(append([]common.Address{}, builtins...), registered...)
+	activeRegisteredCache.Store(key, addrs)
+	return addrs
+}
+
+// RegisterNamedPrecompile makes a precompiled contract implementation available
+// to the custom precompiles declared in chain configs under the given name. It
+// is meant to be called from init functions, and panics on duplicate names.
+func RegisterNamedPrecompile(name string, contract PrecompiledContract) {
+	checkPrecompilesWritable(fmt.Sprintf("precompile implementation %q", name))
+
+	if _, ok := namedPrecompiles[name]; ok {
+		panic(fmt.Sprintf("precompile implementation %q already registered", name))
+	}
+	namedPrecompiles[name] = contract
+}
+
+// NamedPrecompile retrieves the precompiled contract implementation registered
+// under the given name.
+func NamedPrecompile(name string) (PrecompiledContract, bool) {
+	contract, ok := namedPrecompiles[name]
+	return contract, ok
+}
+
+// CheckCustomPrecompiles verifies that every custom precompiled contract of the
+// chain config is backed by a registered implementation and does not shadow a
+// built-in one.
+func CheckCustomPrecompiles(config *params.ChainConfig) error {
+	for addr, custom := range config.Precompiles {
+		if custom == nil {
+			return fmt.Errorf("precompile %x: missing definition", addr)
+		}
+		if _, ok := PrecompiledContractsBerlin[addr]; ok {
+			return fmt.Errorf("precompile %x: address reserved for built-in contract", addr)
+		}
+		if _, ok := registeredPrecompiles[addr]; ok {
+			return fmt.Errorf("precompile %x: address reserved for registered contract", addr)
+		}
+		if _, ok := NamedPrecompile(custom.Name); !ok {
+			return fmt.Errorf("precompile %x: unknown implementation %q", addr, custom.Name)
+		}
+	}
+	return nil
+}
+
+// customPrecompile retrieves the custom precompiled contract at the given
+// address, if one is active in the current block.
+func (evm *EVM) customPrecompile(addr common.Address) (PrecompiledContract, bool) {
+	name, ok := evm.chainConfig.CustomPrecompile(addr, evm.Context.BlockNumber)
+	if !ok {
+		return nil, false
+	}
+	return NamedPrecompile(name)
+}
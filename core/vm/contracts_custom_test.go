@@ -0,0 +1,173 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// reverseBytes is a custom precompiled contract returning its input reversed.
+type reverseBytes struct{}
+
+func (c *reverseBytes) RequiredGas(input []byte) uint64 { return 100 }
+
+func (c *reverseBytes) Run(input []byte) ([]byte, error) {
+	output := make([]byte, len(input))
+	for i := range input {
+		output[len(input)-1-i] = input[i]
+	}
+	return output, nil
+}
+
+// registeredAddress is the address of the contract added via the registry.
+var registeredAddress = common.HexToAddress("0x0000000000000000000000000000000000000200")
+
+func init() {
+	RegisterNamedPrecompile("test-reverse", &reverseBytes{})
+	RegisterPrecompile(registeredAddress, &reverseBytes{}, func(rules params.Rules) bool { return rules.IsLondon })
+}
+
+// Tests that custom precompiled contracts declared in the chain config are only
+// callable from their activation block.
+func TestCustomPrecompile(t *testing.T) {
+	var (
+		address = common.HexToAddress("0x0000000000000000000000000000000000000100")
+		config  = *params.TestChainConfig
+	)
+	config.Precompiles = map[common.Address]*params.CustomPrecompile{
+		address: {Name: "test-reverse", Block: big.NewInt(10)},
+	}
+	if err := CheckCustomPrecompiles(&config); err != nil {
+		t.Fatalf("failed to validate precompiles: %v", err)
+	}
+	for _, tt := range []struct {
+		number uint64
+		active bool
+	}{{9, false}, {10, true}, {11, true}} {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: new(big.Int).SetUint64(tt.number),
+		}
+		vmenv := NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+
+		ret, gas, err := vmenv.Call(AccountRef(common.Address{}), address, []byte{1, 2, 3}, 1000, new(big.Int))
+		if err != nil {
+			t.Fatalf("block %d: call failed: %v", tt.number, err)
+		}
+		if tt.active {
+			if !bytes.Equal(ret, []byte{3, 2, 1}) || gas != 900 {
+				t.Errorf("block %d: precompile not executed: ret %x, gas %d", tt.number, ret, gas)
+			}
+		} else if len(ret) != 0 || gas != 1000 {
+			t.Errorf("block %d: precompile executed before activation: ret %x, gas %d", tt.number, ret, gas)
+		}
+		var listed bool
+		for _, addr := range ActivePrecompiles(config.Rules(vmctx.BlockNumber)) {
+			listed = listed || addr == address
+		}
+		if listed != tt.active {
+			t.Errorf("block %d: active precompile listing mismatch: have %v, want %v", tt.number, listed, tt.active)
+		}
+	}
+}
+
+// Tests that chain configs referencing unknown or shadowing contracts are rejected.
+func TestCheckCustomPrecompiles(t *testing.T) {
+	config := *params.TestChainConfig
+
+	config.Precompiles = map[common.Address]*params.CustomPrecompile{
+		common.HexToAddress("0x0100"): {Name: "unknown"},
+	}
+	if err := CheckCustomPrecompiles(&config); err == nil {
+		t.Error("unknown implementation accepted")
+	}
+	config.Precompiles = map[common.Address]*params.CustomPrecompile{
+		common.BytesToAddress([]byte{1}): {Name: "test-reverse"},
+	}
+	if err := CheckCustomPrecompiles(&config); err == nil {
+		t.Error("built-in precompile shadowing accepted")
+	}
+}
+
+// Tests that precompiled contracts added through the registry are callable only
+// when their activation rules allow.
+func TestRegisteredPrecompile(t *testing.T) {
+	config := *params.TestChainConfig
+	config.LondonBlock = big.NewInt(10)
+
+	for _, tt := range []struct {
+		number uint64
+		active bool
+	}{{9, false}, {10, true}} {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: new(big.Int).SetUint64(tt.number),
+			BaseFee:     new(big.Int),
+		}
+		vmenv := NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+
+		ret, _, err := vmenv.Call(AccountRef(common.Address{}), registeredAddress, []byte{1, 2, 3}, 1000, new(big.Int))
+		if err != nil {
+			t.Fatalf("block %d: call failed: %v", tt.number, err)
+		}
+		if executed := bytes.Equal(ret, []byte{3, 2, 1}); executed != tt.active {
+			t.Errorf("block %d: precompile execution mismatch: have %v, want %v", tt.number, executed, tt.active)
+		}
+		var listed bool
+		for _, addr := range ActivePrecompiles(config.Rules(vmctx.BlockNumber)) {
+			listed = listed || addr == registeredAddress
+		}
+		if listed != tt.active {
+			t.Errorf("block %d: active precompile listing mismatch: have %v, want %v", tt.number, listed, tt.active)
+		}
+	}
+	// Genesis defined contracts must not clash with registered ones
+	config.Precompiles = map[common.Address]*params.CustomPrecompile{
+		registeredAddress: {Name: "test-reverse"},
+	}
+	if err := CheckCustomPrecompiles(&config); err == nil {
+		t.Error("registered precompile shadowing accepted")
+	}
+}
+
+// Tests that the precompile registry rejects registrations once an EVM exists,
+// as it is read without locking from then on.
+func TestRegisterPrecompileFrozen(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	NewEVM(BlockContext{BlockNumber: new(big.Int)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registration after EVM creation accepted")
+		}
+		if _, ok := registeredPrecompiles[common.HexToAddress("0x0300")]; ok {
+			t.Error("late registration stored")
+		}
+	}()
+	RegisterPrecompile(common.HexToAddress("0x0300"), &reverseBytes{}, nil)
+}
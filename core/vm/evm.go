@@ -53,8 +53,16 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 	default:
 		precompiles = PrecompiledContractsHomestead
 	}
-	p, ok := precompiles[addr]
-	return p, ok
+	if p, ok := precompiles[addr]; ok {
+		return p, true
+	}
+	if p, ok := registeredPrecompileAt(addr, evm.chainRules); ok {
+		return p, true
+	}
+	if len(evm.chainRules.CustomPrecompiles) > 0 {
+		return evm.customPrecompile(addr)
+	}
+	return nil, false
 }
 
 // BlockContext provides the EVM with auxiliary information. Once provided
@@ -125,6 +133,8 @@ type EVM struct {
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
 // only ever be used *once*.
 func NewEVM(blockCtx BlockContext, txCtx TxContext, statedb StateDB, chainConfig *params.ChainConfig, config Config) *EVM {
+	freezePrecompiles()
+
 	evm := &EVM{
 		Context:     blockCtx,
 		TxContext:   txCtx,
@@ -105,3 +105,114 @@ func TestEIP2200(t *testing.T) {
 		}
 	}
 }
+
+// Tests that opcode gas overrides of the chain config are charged from their
+// activation block, without affecting the shared instruction sets.
+func TestOpcodeGasOverride(t *testing.T) {
+	config := *params.TestChainConfig
+	config.OpcodeGas = map[string]*params.OpcodeGas{
+		"ADDRESS": {Gas: 100, Block: big.NewInt(10)},
+	}
+	if err := CheckOpcodeGas(&config); err != nil {
+		t.Fatalf("failed to validate opcode gas overrides: %v", err)
+	}
+	address := common.BytesToAddress([]byte("contract"))
+	for _, tt := range []struct {
+		number uint64
+		used   uint64
+	}{{9, GasQuickStep}, {10, 100}} {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, []byte{byte(ADDRESS), byte(STOP)})
+
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: new(big.Int).SetUint64(tt.number),
+			BaseFee:     new(big.Int),
+		}
+		vmenv := NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+
+		_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 1000, new(big.Int))
+		if err != nil {
+			t.Fatalf("block %d: call failed: %v", tt.number, err)
+		}
+		if used := 1000 - gas; used != tt.used {
+			t.Errorf("block %d: gas used mismatch: have %d, want %d", tt.number, used, tt.used)
+		}
+	}
+	if gas := londonInstructionSet[ADDRESS].constantGas; gas != GasQuickStep {
+		t.Errorf("shared instruction set modified: have %d, want %d", gas, GasQuickStep)
+	}
+	config.OpcodeGas = map[string]*params.OpcodeGas{"NOPE": {Gas: 100}}
+	if err := CheckOpcodeGas(&config); err == nil {
+		t.Error("unknown opcode accepted")
+	}
+}
+
+// Tests that SLOAD, which is priced by dynamic gas since EIP-2929, can be
+// repriced as a whole, even if the EIP is enabled on top of the fork rules,
+// while still warming the accessed slots.
+func TestOpcodeGasOverrideDynamic(t *testing.T) {
+	address := common.BytesToAddress([]byte("contract"))
+	code := []byte{
+		byte(PUSH1), 0, byte(SLOAD), byte(POP),
+		byte(PUSH1), 0, byte(SLOAD), byte(POP),
+		byte(STOP),
+	}
+	istanbul := *params.TestChainConfig
+	istanbul.BerlinBlock, istanbul.LondonBlock = nil, nil
+
+	for i, tt := range []struct {
+		config *params.ChainConfig
+		eips   []int
+		gas    *params.OpcodeGas
+		used   uint64
+	}{
+		// Plain London rules: cold and warm access
+		{config: params.TestChainConfig, used: 3 + 2 + 2100 + 3 + 2 + 100},
+		// Constant gas only overrides are charged on top of the access costs
+		{config: params.TestChainConfig, gas: &params.OpcodeGas{Gas: 10}, used: 3 + 2 + 2110 + 3 + 2 + 110},
+		// Dynamic overrides price SLOAD as a whole
+		{config: params.TestChainConfig, gas: &params.OpcodeGas{Gas: 500, Dynamic: true}, used: 3 + 2 + 500 + 3 + 2 + 500},
+		// Overrides apply after the EIPs enabled on top of the fork rules
+		{config: &istanbul, eips: []int{2929}, gas: &params.OpcodeGas{Gas: 500, Dynamic: true}, used: 3 + 2 + 500 + 3 + 2 + 500},
+	} {
+		config := *tt.config
+		if tt.gas != nil {
+			config.OpcodeGas = map[string]*params.OpcodeGas{"SLOAD": tt.gas}
+			if err := CheckOpcodeGas(&config); err != nil {
+				t.Fatalf("test %d: failed to validate opcode gas overrides: %v", i, err)
+			}
+		}
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, code)
+		statedb.PrepareAccessList(common.Address{}, &address, nil, nil)
+
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: new(big.Int),
+			BaseFee:     new(big.Int),
+		}
+		vmenv := NewEVM(vmctx, TxContext{}, statedb, &config, Config{ExtraEips: tt.eips})
+
+		_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 10000, new(big.Int))
+		if err != nil {
+			t.Fatalf("test %d: call failed: %v", i, err)
+		}
+		if used := 10000 - gas; used != tt.used {
+			t.Errorf("test %d: gas used mismatch: have %d, want %d", i, used, tt.used)
+		}
+		if _, warm := statedb.SlotInAccessList(address, common.Hash{}); !warm {
+			t.Errorf("test %d: accessed slot not warmed", i)
+		}
+	}
+	// Memory expanding opcodes must keep their dynamic costs
+	config := *params.TestChainConfig
+	config.OpcodeGas = map[string]*params.OpcodeGas{"MSTORE": {Gas: 1, Dynamic: true}}
+	if err := CheckOpcodeGas(&config); err == nil {
+		t.Error("dynamic override of memory expanding opcode accepted")
+	}
+}
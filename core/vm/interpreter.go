@@ -19,6 +19,7 @@ package vm
 import (
 	"hash"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -33,6 +34,8 @@ type Config struct {
 	NoBaseFee               bool   // Forces the EIP-1559 baseFee to 0 (needed for 0 price calls)
 	EnablePreimageRecording bool   // Enables recording of SHA3/keccak preimages
 
+	OpcodeStats *OpcodeStats // Per-opcode execution statistics collector, if enabled
+
 	JumpTable [256]*operation // EVM instruction table, automatically populated if unset
 
 	ExtraEips []int // Additional EIPS that are to be enabled
@@ -72,26 +75,11 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 	// the jump table was initialised. If it was not
 	// we'll set the default jump table.
 	if cfg.JumpTable[STOP] == nil {
-		var jt JumpTable
-		switch {
-		case evm.chainRules.IsLondon:
-			jt = londonInstructionSet
-		case evm.chainRules.IsBerlin:
-			jt = berlinInstructionSet
-		case evm.chainRules.IsIstanbul:
-			jt = istanbulInstructionSet
-		case evm.chainRules.IsConstantinople:
-			jt = constantinopleInstructionSet
-		case evm.chainRules.IsByzantium:
-			jt = byzantiumInstructionSet
-		case evm.chainRules.IsEIP158:
-			jt = spuriousDragonInstructionSet
-		case evm.chainRules.IsEIP150:
-			jt = tangerineWhistleInstructionSet
-		case evm.chainRules.IsHomestead:
-			jt = homesteadInstructionSet
-		default:
-			jt = frontierInstructionSet
+		jt := instructionSetForRules(evm.chainRules)
+		if len(cfg.ExtraEips) > 0 {
+			// The EIP activators modify the operations in place, which are
+			// shared by all the interpreters of the fork
+			jt = copyJumpTable(&jt)
 		}
 		for i, eip := range cfg.ExtraEips {
 			if err := EnableEIP(eip, &jt); err != nil {
@@ -100,6 +88,7 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 				log.Error("EIP activation failed", "eip", eip, "error", err)
 			}
 		}
+		applyOpcodeGas(&jt, evm.chainRules.OpcodeGas)
 		cfg.JumpTable = jt
 	}
 
@@ -258,7 +247,24 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		}
 
 		// execute the operation
+		var (
+			start     time.Time
+			forwarded uint64
+		)
+		if in.cfg.OpcodeStats != nil {
+			// The cost of calls includes the gas forwarded to the callee, which
+			// is partially returned and accounted to the callee's opcodes. Only
+			// charge the gas consumed by the call itself.
+			switch op {
+			case CALL, CALLCODE, DELEGATECALL, STATICCALL:
+				forwarded = in.evm.callGasTemp
+			}
+			start = time.Now()
+		}
 		res, err = operation.execute(&pc, in, callContext)
+		if in.cfg.OpcodeStats != nil {
+			in.cfg.OpcodeStats.record(op, cost-forwarded, time.Since(start))
+		}
 		// if the operation clears the return data (e.g. it has returning data)
 		// set the last return to the result of the operation.
 		if operation.returns {
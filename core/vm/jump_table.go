@@ -17,6 +17,8 @@
 package vm
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -63,6 +65,97 @@ var (
 // JumpTable contains the EVM opcodes supported at a given fork.
 type JumpTable [256]*operation
 
+// instructionSetForRules returns the instructions of the fork active under the
+// given chain rules.
+func instructionSetForRules(rules params.Rules) JumpTable {
+	switch {
+	case rules.IsLondon:
+		return londonInstructionSet
+	case rules.IsBerlin:
+		return berlinInstructionSet
+	case rules.IsIstanbul:
+		return istanbulInstructionSet
+	case rules.IsConstantinople:
+		return constantinopleInstructionSet
+	case rules.IsByzantium:
+		return byzantiumInstructionSet
+	case rules.IsEIP158:
+		return spuriousDragonInstructionSet
+	case rules.IsEIP150:
+		return tangerineWhistleInstructionSet
+	case rules.IsHomestead:
+		return homesteadInstructionSet
+	default:
+		return frontierInstructionSet
+	}
+}
+
+// copyJumpTable returns a deep copy of the jump table, whose operations can be
+// modified without affecting the source.
+func copyJumpTable(source *JumpTable) JumpTable {
+	var jt JumpTable
+	for i, op := range source {
+		if op != nil {
+			cpy := *op
+			jt[i] = &cpy
+		}
+	}
+	return jt
+}
+
+// applyOpcodeGas applies the opcode gas overrides of the chain to the jump table.
+// It needs to run after all the EIPs are enabled, as those may reprice opcodes.
+func applyOpcodeGas(jt *JumpTable, overrides map[string]params.OpcodeGas) {
+	for name, override := range overrides {
+		// Skip opcodes unknown or not yet introduced in the active fork
+		op := StringToOp(name)
+		if op.String() != name || jt[op] == nil {
+			continue
+		}
+		// The operations are shared between tables, so override a copy
+		operation := *jt[op]
+		operation.constantGas = override.Gas
+		if override.Dynamic && operation.dynamicGas != nil {
+			operation.dynamicGas = flatDynamicGas(operation.dynamicGas)
+		}
+		jt[op] = &operation
+	}
+}
+
+// flatDynamicGas wraps a dynamic gas function, charging nothing on top of the
+// constant gas. The original function is still run, since it may have side
+// effects (EIP-2929 access list warming) or reject the operation (EIP-2200 gas
+// sentry).
+func flatDynamicGas(dynamic gasFunc) gasFunc {
+	return func(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		if _, err := dynamic(evm, contract, stack, mem, memorySize); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+}
+
+// CheckOpcodeGas verifies that every opcode gas override of the chain config
+// references a known opcode, and that dynamic costs are only replaced where no
+// memory expansion is charged.
+func CheckOpcodeGas(config *params.ChainConfig) error {
+	for name, override := range config.OpcodeGas {
+		if override == nil {
+			return fmt.Errorf("opcode %s: missing gas override", name)
+		}
+		op := StringToOp(name)
+		if op.String() != name {
+			return fmt.Errorf("opcode %s: unknown opcode", name)
+		}
+		if override.Dynamic {
+			if operation := londonInstructionSet[op]; operation != nil && operation.memorySize != nil {
+				return fmt.Errorf("opcode %s: dynamic gas includes memory expansion, cannot be overridden", name)
+			}
+		}
+	}
+	return nil
+}
+
 // newLondonInstructionSet returns the frontier, homestead, byzantium,
 // contantinople, istanbul, petersburg, berlin and london instructions.
 func newLondonInstructionSet() JumpTable {
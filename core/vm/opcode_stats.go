@@ -0,0 +1,103 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// OpcodeStat is the aggregated execution statistics of a single opcode.
+type OpcodeStat struct {
+	Count uint64        `json:"count"` // Number of times the opcode was executed
+	Gas   uint64        `json:"gas"`   // Cumulative gas charged by the opcode
+	Time  time.Duration `json:"time"`  // Cumulative execution time, including inner calls
+}
+
+// OpcodeStats collects per-opcode execution counts, gas and time for profiling
+// the workloads run by the EVM. It is safe for concurrent use by multiple EVMs.
+type OpcodeStats struct {
+	counts [256]uint64
+	gas    [256]uint64
+	time   [256]uint64
+
+	countMeters [256]metrics.Counter
+	gasMeters   [256]metrics.Counter
+	timeMeters  [256]metrics.Counter
+}
+
+// NewOpcodeStats creates an opcode statistics collector, exporting the collected
+// values of all defined opcodes through the metrics registry too.
+func NewOpcodeStats() *OpcodeStats {
+	stats := new(OpcodeStats)
+	for i := 0; i < 256; i++ {
+		op := OpCode(i)
+		if strings.HasPrefix(op.String(), "opcode ") {
+			continue // undefined opcode, execution will fail anyway
+		}
+		name := strings.ToLower(op.String())
+		stats.countMeters[i] = metrics.GetOrRegisterCounter(fmt.Sprintf("vm/opcode/%s/count", name), nil)
+		stats.gasMeters[i] = metrics.GetOrRegisterCounter(fmt.Sprintf("vm/opcode/%s/gas", name), nil)
+		stats.timeMeters[i] = metrics.GetOrRegisterCounter(fmt.Sprintf("vm/opcode/%s/time", name), nil)
+	}
+	return stats
+}
+
+// record accounts a single execution of an opcode.
+func (s *OpcodeStats) record(op OpCode, gas uint64, elapsed time.Duration) {
+	atomic.AddUint64(&s.counts[op], 1)
+	atomic.AddUint64(&s.gas[op], gas)
+	atomic.AddUint64(&s.time[op], uint64(elapsed))
+
+	if meter := s.countMeters[op]; meter != nil {
+		meter.Inc(1)
+		s.gasMeters[op].Inc(int64(gas))
+		s.timeMeters[op].Inc(int64(elapsed))
+	}
+}
+
+// Stats returns the statistics of all the opcodes executed so far, keyed by the
+// opcode name.
+func (s *OpcodeStats) Stats() map[string]OpcodeStat {
+	stats := make(map[string]OpcodeStat)
+	for i := 0; i < 256; i++ {
+		count := atomic.LoadUint64(&s.counts[i])
+		if count == 0 {
+			continue
+		}
+		stats[OpCode(i).String()] = OpcodeStat{
+			Count: count,
+			Gas:   atomic.LoadUint64(&s.gas[i]),
+			Time:  time.Duration(atomic.LoadUint64(&s.time[i])),
+		}
+	}
+	return stats
+}
+
+// Reset clears all the statistics collected so far. The exported metrics are
+// left untouched, as they are cumulative.
+func (s *OpcodeStats) Reset() {
+	for i := 0; i < 256; i++ {
+		atomic.StoreUint64(&s.counts[i], 0)
+		atomic.StoreUint64(&s.gas[i], 0)
+		atomic.StoreUint64(&s.time[i], 0)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the per-opcode statistics account all executed opcodes along with
+// the gas they were charged.
+func TestOpcodeStats(t *testing.T) {
+	address := common.BytesToAddress([]byte("contract"))
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, []byte{byte(PUSH1), 0x01, byte(PUSH1), 0x02, byte(ADD), byte(POP), byte(STOP)})
+
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+		BaseFee:     new(big.Int),
+	}
+	stats := NewOpcodeStats()
+	vmenv := NewEVM(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{OpcodeStats: stats})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 1000, new(big.Int)); err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+	}
+	want := map[string]OpcodeStat{
+		"PUSH1": {Count: 4, Gas: 4 * GasFastestStep},
+		"ADD":   {Count: 2, Gas: 2 * GasFastestStep},
+		"POP":   {Count: 2, Gas: 2 * GasQuickStep},
+		"STOP":  {Count: 2},
+	}
+	have := stats.Stats()
+	if len(have) != len(want) {
+		t.Fatalf("opcode count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for name, stat := range want {
+		if have[name].Count != stat.Count || have[name].Gas != stat.Gas {
+			t.Errorf("%s: stats mismatch: have %d/%d, want %d/%d", name, have[name].Count, have[name].Gas, stat.Count, stat.Gas)
+		}
+	}
+	stats.Reset()
+	if have := stats.Stats(); len(have) != 0 {
+		t.Errorf("stats not reset: %v", have)
+	}
+}
+
+// Tests that calls are only charged their own gas, not the gas forwarded to the
+// callee, whose opcodes are accounted on their own.
+func TestOpcodeStatsCall(t *testing.T) {
+	var (
+		caller = common.BytesToAddress([]byte("caller"))
+		callee = common.BytesToAddress([]byte("callee"))
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(callee)
+	statedb.SetCode(callee, []byte{byte(PUSH1), 0x01, byte(POP), byte(STOP)})
+
+	code := []byte{
+		byte(PUSH1), 0x00, byte(PUSH1), 0x00, byte(PUSH1), 0x00, byte(PUSH1), 0x00, byte(PUSH1), 0x00, // ret, args and value
+		byte(PUSH20),
+	}
+	code = append(code, callee.Bytes()...)
+	code = append(code, byte(GAS), byte(CALL), byte(POP), byte(STOP))
+	statedb.CreateAccount(caller)
+	statedb.SetCode(caller, code)
+
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+		BaseFee:     new(big.Int),
+	}
+	stats := NewOpcodeStats()
+	vmenv := NewEVM(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{OpcodeStats: stats})
+
+	_, leftover, err := vmenv.Call(AccountRef(common.Address{}), caller, nil, 100000, new(big.Int))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	want := map[string]OpcodeStat{
+		"PUSH1":  {Count: 6, Gas: 6 * GasFastestStep},
+		"PUSH20": {Count: 1, Gas: GasFastestStep},
+		"GAS":    {Count: 1, Gas: GasQuickStep},
+		"CALL":   {Count: 1, Gas: params.ColdAccountAccessCostEIP2929},
+		"POP":    {Count: 2, Gas: 2 * GasQuickStep},
+		"STOP":   {Count: 2},
+	}
+	have := stats.Stats()
+	if len(have) != len(want) {
+		t.Fatalf("opcode count mismatch: have %d, want %d", len(have), len(want))
+	}
+	var total uint64
+	for name, stat := range want {
+		if have[name].Count != stat.Count || have[name].Gas != stat.Gas {
+			t.Errorf("%s: stats mismatch: have %d/%d, want %d/%d", name, have[name].Count, have[name].Gas, stat.Count, stat.Gas)
+		}
+		total += have[name].Gas
+	}
+	if used := 100000 - leftover; total != used {
+		t.Errorf("total gas mismatch: have %d, want %d", total, used)
+	}
+}
@@ -19,6 +19,7 @@ package eth
 import (
 	"compress/gzip"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -34,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -66,6 +68,69 @@ func (api *PublicEthereumAPI) Hashrate() hexutil.Uint64 {
 	return hexutil.Uint64(api.e.Miner().Hashrate())
 }
 
+// AddressTransactionsMaxResults is the maximum number of results to be returned per call
+const AddressTransactionsMaxResults = 1024
+
+// AddressTransaction is a reference to a canonical transaction sent from or to
+// an indexed address.
+type AddressTransaction struct {
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+	Hash             common.Hash    `json:"hash"`
+}
+
+// AddressTransactions is the result of an eth_getTransactionsByAddress API call.
+type AddressTransactions struct {
+	Transactions []AddressTransaction `json:"transactions"`
+	Next         hexutil.Bytes        `json:"next,omitempty"` // nil if no more transactions
+}
+
+// GetTransactionsByAddress pages through the transactions sent from or to the
+// given address, ordered by chain position. The start cursor is either empty,
+// or the next field returned by a previous call.
+func (api *PublicEthereumAPI) GetTransactionsByAddress(address common.Address, start hexutil.Bytes, maxResults int) (*AddressTransactions, error) {
+	if !api.e.config.AddressTxIndex {
+		return nil, errors.New("address transaction index is disabled")
+	}
+	var (
+		number uint64
+		index  uint32
+	)
+	switch len(start) {
+	case 0:
+	case 12:
+		number, index = binary.BigEndian.Uint64(start), binary.BigEndian.Uint32(start[8:])
+	default:
+		return nil, fmt.Errorf("invalid start cursor length %d", len(start))
+	}
+	if maxResults > AddressTransactionsMaxResults || maxResults <= 0 {
+		maxResults = AddressTransactionsMaxResults
+	}
+	// Retrieve one extra entry to find out where the next page starts
+	entries := rawdb.ReadAddressTxEntries(api.e.chainDb, address, number, index, maxResults+1)
+
+	result := &AddressTransactions{Transactions: []AddressTransaction{}}
+	if len(entries) > maxResults {
+		next := entries[maxResults]
+		result.Next = make(hexutil.Bytes, 12)
+		binary.BigEndian.PutUint64(result.Next, next.BlockNumber)
+		binary.BigEndian.PutUint32(result.Next[8:], next.Index)
+		entries = entries[:maxResults]
+	}
+	for _, entry := range entries {
+		// Skip stale entries left behind by reorgs while the index was disabled
+		if number := rawdb.ReadTxLookupEntry(api.e.chainDb, entry.Hash); number == nil || *number != entry.BlockNumber {
+			continue
+		}
+		result.Transactions = append(result.Transactions, AddressTransaction{
+			BlockNumber:      hexutil.Uint64(entry.BlockNumber),
+			TransactionIndex: hexutil.Uint(entry.Index),
+			Hash:             entry.Hash,
+		})
+	}
+	return result, nil
+}
+
 // PublicMinerAPI provides an API to control the miner.
 // It offers only methods that operate on data that pose no security risk when it is publicly accessible.
 type PublicMinerAPI struct {
@@ -158,6 +223,71 @@ func NewPrivateAdminAPI(eth *Ethereum) *PrivateAdminAPI {
 	return &PrivateAdminAPI{eth: eth}
 }
 
+// TxPoolQuotas are the slot quotas of the transaction pool. When updating the
+// quotas, omitted fields retain their current values.
+type TxPoolQuotas struct {
+	AccountSlots *hexutil.Uint64 `json:"accountSlots"`
+	GlobalSlots  *hexutil.Uint64 `json:"globalSlots"`
+	AccountQueue *hexutil.Uint64 `json:"accountQueue"`
+	GlobalQueue  *hexutil.Uint64 `json:"globalQueue"`
+}
+
+// TxPoolQuotas returns the slot quotas currently enforced by the transaction pool.
+func (api *PrivateAdminAPI) TxPoolQuotas() TxPoolQuotas {
+	accountSlots, globalSlots, accountQueue, globalQueue := api.eth.TxPool().SlotQuotas()
+	return TxPoolQuotas{
+		AccountSlots: (*hexutil.Uint64)(&accountSlots),
+		GlobalSlots:  (*hexutil.Uint64)(&globalSlots),
+		AccountQueue: (*hexutil.Uint64)(&accountQueue),
+		GlobalQueue:  (*hexutil.Uint64)(&globalQueue),
+	}
+}
+
+// SetTxPoolQuotas updates the slot quotas of the transaction pool, dropping the
+// transactions exceeding the new limits.
+func (api *PrivateAdminAPI) SetTxPoolQuotas(quotas TxPoolQuotas) (TxPoolQuotas, error) {
+	accountSlots, globalSlots, accountQueue, globalQueue := api.eth.TxPool().SlotQuotas()
+	if quotas.AccountSlots != nil {
+		accountSlots = uint64(*quotas.AccountSlots)
+	}
+	if quotas.GlobalSlots != nil {
+		globalSlots = uint64(*quotas.GlobalSlots)
+	}
+	if quotas.AccountQueue != nil {
+		accountQueue = uint64(*quotas.AccountQueue)
+	}
+	if quotas.GlobalQueue != nil {
+		globalQueue = uint64(*quotas.GlobalQueue)
+	}
+	if err := api.eth.TxPool().SetSlotQuotas(accountSlots, globalSlots, accountQueue, globalQueue); err != nil {
+		return TxPoolQuotas{}, err
+	}
+	return api.TxPoolQuotas(), nil
+}
+
+// CompactTxJournal regenerates the local transaction journal, dropping all the
+// included and invalidated transactions from it.
+func (api *PrivateAdminAPI) CompactTxJournal() (bool, error) {
+	if err := api.eth.TxPool().CompactJournal(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveTx drops a transaction and all the subsequent ones of the same sender
+// from the pool, returning the hashes of the removed transactions.
+func (api *PrivateAdminAPI) RemoveTx(hash common.Hash) ([]common.Hash, error) {
+	drops := api.eth.TxPool().RemoveTx(hash)
+	if len(drops) == 0 {
+		return nil, fmt.Errorf("transaction %x not found in pool", hash)
+	}
+	hashes := make([]common.Hash, len(drops))
+	for i, tx := range drops {
+		hashes[i] = tx.Hash()
+	}
+	return hashes, nil
+}
+
 // ExportChain exports the current blockchain into a local file,
 // or a range of blocks if first and last are non-nil
 func (api *PrivateAdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool, error) {
@@ -268,18 +398,28 @@ func NewPublicDebugAPI(eth *Ethereum) *PublicDebugAPI {
 	return &PublicDebugAPI{eth: eth}
 }
 
-// DumpBlock retrieves the entire state of the database at a given block.
-func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error) {
+// DumpBlock retrieves the state of the database at a given block. The accounts
+// are returned in pages of at most AccountRangeMaxResults, the optional start
+// key and result limit permitting iteration over states too large for a single
+// call. The next key to continue from is included in the result, encoded the
+// same way as the start key.
+func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber, start *[]byte, maxResults *int) (state.IteratorDump, error) {
 	opts := &state.DumpConfig{
 		OnlyWithAddresses: true,
 		Max:               AccountRangeMaxResults, // Sanity limit over RPC
 	}
+	if start != nil {
+		opts.Start = *start
+	}
+	if maxResults != nil && *maxResults > 0 && *maxResults < AccountRangeMaxResults {
+		opts.Max = uint64(*maxResults)
+	}
 	if blockNr == rpc.PendingBlockNumber {
 		// If we're dumping the pending state, we need to request
 		// both the pending block as well as the pending state from
 		// the miner and operate on those
 		_, stateDb := api.eth.miner.Pending()
-		return stateDb.RawDump(opts), nil
+		return stateDb.IteratorDump(opts), nil
 	}
 	var block *types.Block
 	if blockNr == rpc.LatestBlockNumber {
@@ -288,13 +428,13 @@ func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error
 		block = api.eth.blockchain.GetBlockByNumber(uint64(blockNr))
 	}
 	if block == nil {
-		return state.Dump{}, fmt.Errorf("block #%d not found", blockNr)
+		return state.IteratorDump{}, fmt.Errorf("block #%d not found", blockNr)
 	}
 	stateDb, err := api.eth.BlockChain().StateAt(block.Root())
 	if err != nil {
-		return state.Dump{}, err
+		return state.IteratorDump{}, err
 	}
-	return stateDb.RawDump(opts), nil
+	return stateDb.IteratorDump(opts), nil
 }
 
 // PrivateDebugAPI is the collection of Ethereum full node APIs exposed over
@@ -317,11 +457,32 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 	return nil, errors.New("unknown preimage")
 }
 
+// OpcodeStats returns the per-opcode execution statistics collected during block
+// processing since startup or the last reset.
+func (api *PrivateDebugAPI) OpcodeStats() (map[string]vm.OpcodeStat, error) {
+	stats := api.eth.blockchain.GetVMConfig().OpcodeStats
+	if stats == nil {
+		return nil, errors.New("opcode metrics not enabled")
+	}
+	return stats.Stats(), nil
+}
+
+// ResetOpcodeStats clears the per-opcode execution statistics collected so far.
+func (api *PrivateDebugAPI) ResetOpcodeStats() error {
+	stats := api.eth.blockchain.GetVMConfig().OpcodeStats
+	if stats == nil {
+		return errors.New("opcode metrics not enabled")
+	}
+	stats.Reset()
+	return nil
+}
+
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
-	Hash  common.Hash            `json:"hash"`
-	Block map[string]interface{} `json:"block"`
-	RLP   string                 `json:"rlp"`
+	Hash   common.Hash            `json:"hash"`
+	Block  map[string]interface{} `json:"block"`
+	RLP    string                 `json:"rlp"`
+	Reason string                 `json:"reason,omitempty"`
 }
 
 // GetBadBlocks returns a list of the last 'bad blocks' that the client has seen on the network
@@ -346,9 +507,10 @@ func (api *PrivateDebugAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs,
 			blockJSON = map[string]interface{}{"error": err.Error()}
 		}
 		results = append(results, &BadBlockArgs{
-			Hash:  block.Hash(),
-			RLP:   blockRlp,
-			Block: blockJSON,
+			Hash:   block.Hash(),
+			RLP:    blockRlp,
+			Block:  blockJSON,
+			Reason: rawdb.ReadBadBlockReason(api.eth.chainDb, block.Hash()),
 		})
 	}
 	return results, nil
@@ -23,12 +23,18 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 var dumper = spew.ConfigState{Indent: "    "}
@@ -218,3 +224,76 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+// Tests that debug_dumpBlock can page through the whole state over RPC, feeding
+// the returned next key back as the start key, without returning any account
+// twice.
+func TestDumpBlockPagination(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{}}
+	)
+	for i := 1; i <= 25; i++ {
+		gspec.Alloc[common.BigToAddress(big.NewInt(int64(i)))] = core.GenesisAccount{Balance: big.NewInt(int64(i))}
+	}
+	gspec.MustCommit(db)
+
+	// Preimages are needed to dump accounts by address
+	cacheConfig := &core.CacheConfig{TrieCleanLimit: 16, TrieDirtyLimit: 16, TrieTimeLimit: time.Minute, Preimages: true}
+	chain, err := core.NewBlockChain(db, cacheConfig, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("debug", NewPublicDebugAPI(&Ethereum{blockchain: chain})); err != nil {
+		t.Fatalf("failed to register debug API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	// The start key and result limit are optional
+	var dump state.IteratorDump
+	if err := client.Call(&dump, "debug_dumpBlock", "latest"); err != nil {
+		t.Fatalf("failed to dump block: %v", err)
+	}
+	if len(dump.Accounts) != len(gspec.Alloc) || dump.Next != nil {
+		t.Fatalf("full dump mismatch: have %d accounts (next %x), want %d", len(dump.Accounts), dump.Next, len(gspec.Alloc))
+	}
+	var (
+		seen     = make(map[common.Address]bool)
+		start    []byte
+		pageSize = 10
+		pages    int
+	)
+	for {
+		var dump state.IteratorDump
+		if err := client.Call(&dump, "debug_dumpBlock", "latest", start, pageSize); err != nil {
+			t.Fatalf("page %d: failed to dump block: %v", pages, err)
+		}
+		if len(dump.Accounts) > pageSize {
+			t.Fatalf("page %d: too many accounts: have %d, want at most %d", pages, len(dump.Accounts), pageSize)
+		}
+		for addr := range dump.Accounts {
+			if seen[addr] {
+				t.Errorf("page %d: account %x returned twice", pages, addr)
+			}
+			seen[addr] = true
+		}
+		pages++
+		if dump.Next == nil {
+			break
+		}
+		start = dump.Next
+	}
+	if pages != 3 {
+		t.Errorf("page count mismatch: have %d, want %d", pages, 3)
+	}
+	for addr := range gspec.Alloc {
+		if !seen[addr] {
+			t.Errorf("account %x missing from dump", addr)
+		}
+	}
+}
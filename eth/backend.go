@@ -83,6 +83,8 @@ type Ethereum struct {
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
 
+	badBlockReporter *badBlockReporter // Optional remote reporter of blocks failing validation
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -111,6 +113,15 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.Miner.GasPrice, "updated", ethconfig.Defaults.Miner.GasPrice)
 		config.Miner.GasPrice = new(big.Int).Set(ethconfig.Defaults.Miner.GasPrice)
 	}
+	if config.BloomBitsBlocks == 0 {
+		config.BloomBitsBlocks = params.BloomBitsBlocks
+	}
+	if config.BloomBitsBlocks%8 != 0 {
+		return nil, fmt.Errorf("invalid bloom bits section size %d, must be a multiple of 8", config.BloomBitsBlocks)
+	}
+	if config.BloomConfirms == 0 {
+		config.BloomConfirms = params.BloomConfirms
+	}
 	if config.NoPruning && config.TrieDirtyCache > 0 {
 		if config.SnapshotCache > 0 {
 			config.TrieCleanCache += config.TrieDirtyCache * 3 / 5
@@ -131,7 +142,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideLondon)
+	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.ChainOverrides())
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
 	}
@@ -151,7 +162,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		gasPrice:          config.Miner.GasPrice,
 		etherbase:         config.Miner.Etherbase,
 		bloomRequests:     make(chan chan *bloombits.Retrieval),
-		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
+		bloomIndexer:      core.NewBloomIndexer(chainDb, config.BloomBitsBlocks, config.BloomConfirms),
 		p2pServer:         stack.Server(),
 	}
 
@@ -186,8 +197,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
+			AddressTxIndex:      config.AddressTxIndex,
 		}
 	)
+	if config.EnableOpcodeMetrics {
+		vmConfig.OpcodeStats = vm.NewOpcodeStats()
+	}
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
 	if err != nil {
 		return nil, err
@@ -527,7 +542,12 @@ func (s *Ethereum) Start() error {
 	eth.StartENRUpdater(s.blockchain, s.p2pServer.LocalNode())
 
 	// Start the bloom bits servicing goroutines
-	s.startBloomHandlers(params.BloomBitsBlocks)
+	s.startBloomHandlers(s.config.BloomBitsBlocks)
+
+	// Start forwarding bad blocks if a report endpoint was configured
+	if s.config.BadBlockReportURL != "" {
+		s.badBlockReporter = newBadBlockReporter(s.config.BadBlockReportURL, s.blockchain)
+	}
 
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
@@ -553,6 +573,9 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	if s.badBlockReporter != nil {
+		s.badBlockReporter.stop()
+	}
 	s.txPool.Stop()
 	s.miner.Stop()
 	s.blockchain.Stop()
@@ -0,0 +1,160 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// badBlockReportQueue is the maximum number of bad block reports waiting to
+	// be delivered. Further reports are dropped until the queue drains.
+	badBlockReportQueue = 16
+
+	// badBlockReportTimeout is the maximum time allowed for delivering a report.
+	badBlockReportTimeout = 10 * time.Second
+)
+
+// badBlockReport is the JSON payload posted to the report endpoint.
+type badBlockReport struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+	Reason string         `json:"reason"`
+	RLP    hexutil.Bytes  `json:"rlp"`
+}
+
+// badBlockSource is the subset of the blockchain API the reporter needs.
+type badBlockSource interface {
+	SubscribeBadBlockEvent(ch chan<- core.BadBlockEvent) event.Subscription
+}
+
+// badBlockReporter forwards every block failing validation to a remote HTTP
+// endpoint, so consensus divergences can be diagnosed after the fact.
+type badBlockReporter struct {
+	url    string
+	client *http.Client
+
+	sub     event.Subscription
+	events  chan core.BadBlockEvent
+	reports chan *badBlockReport
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newBadBlockReporter creates a reporter posting the bad blocks announced by
+// the given source to url.
+func newBadBlockReporter(url string, source badBlockSource) *badBlockReporter {
+	r := &badBlockReporter{
+		url:     url,
+		client:  &http.Client{Timeout: badBlockReportTimeout},
+		events:  make(chan core.BadBlockEvent),
+		reports: make(chan *badBlockReport, badBlockReportQueue),
+		closeCh: make(chan struct{}),
+	}
+	r.sub = source.SubscribeBadBlockEvent(r.events)
+
+	r.wg.Add(2)
+	go r.loop()
+	go r.deliver()
+	return r
+}
+
+// loop queues the announced bad blocks for delivery. It never blocks on the
+// network so that block import is not held up by a slow endpoint.
+func (r *badBlockReporter) loop() {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case ev := <-r.events:
+			blob, err := rlp.EncodeToBytes(ev.Block)
+			if err != nil {
+				log.Warn("Failed to encode bad block", "number", ev.Block.Number(), "hash", ev.Block.Hash(), "err", err)
+				continue
+			}
+			report := &badBlockReport{
+				Number: hexutil.Uint64(ev.Block.NumberU64()),
+				Hash:   ev.Block.Hash(),
+				RLP:    blob,
+			}
+			if ev.Err != nil {
+				report.Reason = ev.Err.Error()
+			}
+			select {
+			case r.reports <- report:
+			default:
+				log.Warn("Bad block report queue full, dropping", "number", ev.Block.Number(), "hash", ev.Block.Hash())
+			}
+		case <-r.sub.Err():
+			return
+		case <-r.closeCh:
+			return
+		}
+	}
+}
+
+// deliver posts the queued reports to the remote endpoint.
+func (r *badBlockReporter) deliver() {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case report := <-r.reports:
+			if err := r.post(report); err != nil {
+				log.Warn("Failed to report bad block", "number", uint64(report.Number), "hash", report.Hash, "err", err)
+			}
+		case <-r.closeCh:
+			return
+		}
+	}
+}
+
+// post delivers a single report.
+func (r *badBlockReporter) post(report *badBlockReport) error {
+	blob, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	res, err := r.client.Post(r.url, "application/json", bytes.NewReader(blob))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	return nil
+}
+
+// stop terminates the reporter, dropping any undelivered reports.
+func (r *badBlockReporter) stop() {
+	r.sub.Unsubscribe()
+	close(r.closeCh)
+	r.wg.Wait()
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+type testBadBlockSource struct {
+	feed event.Feed
+}
+
+func (s *testBadBlockSource) SubscribeBadBlockEvent(ch chan<- core.BadBlockEvent) event.Subscription {
+	return s.feed.Subscribe(ch)
+}
+
+// Tests that bad blocks announced by the chain are posted to the report endpoint.
+func TestBadBlockReporter(t *testing.T) {
+	reports := make(chan *badBlockReport, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := new(badBlockReport)
+		if err := json.NewDecoder(r.Body).Decode(report); err != nil {
+			t.Errorf("failed to decode report: %v", err)
+		}
+		reports <- report
+	}))
+	defer server.Close()
+
+	source := new(testBadBlockSource)
+	reporter := newBadBlockReporter(server.URL, source)
+	defer reporter.stop()
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(42), Extra: []byte("bad block")})
+	source.feed.Send(core.BadBlockEvent{Block: block, Err: errors.New("invalid gas used")})
+
+	select {
+	case report := <-reports:
+		if report.Hash != block.Hash() || uint64(report.Number) != 42 {
+			t.Errorf("reported block mismatch: have #%d [%x], want #42 [%x]", report.Number, report.Hash, block.Hash())
+		}
+		if report.Reason != "invalid gas used" {
+			t.Errorf("reported reason mismatch: have %q, want %q", report.Reason, "invalid gas used")
+		}
+		var decoded types.Block
+		if err := rlp.DecodeBytes(report.RLP, &decoded); err != nil {
+			t.Fatalf("failed to decode reported block: %v", err)
+		}
+		if decoded.Hash() != block.Hash() {
+			t.Errorf("reported RLP mismatch: have %x, want %x", decoded.Hash(), block.Hash())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for bad block report")
+	}
+}
@@ -135,20 +135,38 @@ type Config struct {
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
-	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
+	TxLookupLimit  uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
+	AddressTxIndex bool   `toml:",omitempty"` // Whether to index transactions by sender and recipient address
+
+	BloomBitsBlocks uint64 `toml:",omitempty"` // Number of blocks in a single bloom bits section, a multiple of 8 (0 = default)
+	BloomConfirms   uint64 `toml:",omitempty"` // Number of confirmations before a bloom bits section is indexed (0 = default)
 
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
+	// URL to post a report of every block failing validation to
+	BadBlockReportURL string `toml:",omitempty"`
+
 	// Light client options
 	LightServ          int  `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
 	LightIngress       int  `toml:",omitempty"` // Incoming bandwidth limit for light servers
 	LightEgress        int  `toml:",omitempty"` // Outgoing bandwidth limit for light servers
 	LightPeers         int  `toml:",omitempty"` // Maximum number of LES client peers
+	LightPeersPerIP    int  `toml:",omitempty"` // Maximum number of LES client peers from the same IP
 	LightNoPrune       bool `toml:",omitempty"` // Whether to disable light chain pruning
 	LightNoSyncServe   bool `toml:",omitempty"` // Whether to serve light clients before syncing
 	SyncFromCheckpoint bool `toml:",omitempty"` // Whether to sync the header chain from the configured checkpoint
 
+	// Light client request retrieval options
+	LightSoftTimeout     time.Duration `toml:",omitempty"` // Timeout after which a request is resent to another server (0 = adaptive)
+	LightHardTimeout     time.Duration `toml:",omitempty"` // Timeout after which an unresponsive server is dropped (0 = default)
+	LightTxStatusRetries int           `toml:",omitempty"` // Number of retries made retrieving an unknown transaction status (0 = default)
+	LightTxStatusQuorum  int           `toml:",omitempty"` // Number of servers required to report the same transaction status (0 = trust the first)
+	LightStateHistory    uint64        `toml:",omitempty"` // Number of recent blocks whose state can be accessed over ODR (0 = unlimited)
+
+	// Priority light clients, always accepted and not charged for serving
+	LightPriorityClients []string `toml:",omitempty"`
+
 	// Ultra Light client options
 	UltraLightServers      []string `toml:",omitempty"` // List of trusted ultra light servers
 	UltraLightFraction     int      `toml:",omitempty"` // Percentage of trusted servers to accept an announcement
@@ -183,6 +201,9 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables collecting per-opcode execution statistics during block processing
+	EnableOpcodeMetrics bool
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -199,10 +220,32 @@ type Config struct {
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"`
 
-	// Berlin block override (TODO: remove after the fork)
+	// Fork block overrides applied on top of the stored chain configuration
+	Overrides *core.ChainOverrides `toml:",omitempty"`
+
+	// Deprecated: London block override, use Overrides.London instead
 	OverrideLondon *big.Int `toml:",omitempty"`
 }
 
+// ChainOverrides returns the fork block overrides to apply on top of the stored
+// chain configuration, mapping the deprecated OverrideLondon field into them. An
+// explicit Overrides.London takes precedence over the deprecated field.
+func (c *Config) ChainOverrides() *core.ChainOverrides {
+	if c.OverrideLondon == nil {
+		return c.Overrides
+	}
+	log.Warn("Config field OverrideLondon is deprecated, please use Overrides.London", "block", c.OverrideLondon)
+
+	var overrides core.ChainOverrides
+	if c.Overrides != nil {
+		overrides = *c.Overrides
+	}
+	if overrides.London == nil {
+		overrides.London = c.OverrideLondon
+	}
+	return &overrides
+}
+
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
 func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, config *ethash.Config, notify []string, noverify bool, db ethdb.Database) consensus.Engine {
 	// If proof-of-authority is requested, set it up
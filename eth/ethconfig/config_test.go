@@ -0,0 +1,44 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethconfig
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+)
+
+// Tests that the deprecated OverrideLondon field is mapped into the fork block
+// overrides for users configuring the backend programmatically.
+func TestChainOverridesDeprecatedLondon(t *testing.T) {
+	cfg := Defaults
+	cfg.OverrideLondon = big.NewInt(100)
+
+	overrides := cfg.ChainOverrides()
+	if overrides == nil || overrides.London == nil || overrides.London.Uint64() != 100 {
+		t.Fatalf("London override mismatch: have %v, want %d", overrides, 100)
+	}
+	// An explicit London override takes precedence
+	cfg.Overrides = &core.ChainOverrides{London: big.NewInt(50)}
+	if overrides := cfg.ChainOverrides(); overrides.London.Uint64() != 50 {
+		t.Fatalf("London override mismatch: have %v, want %d", overrides.London, 50)
+	}
+	if cfg.Overrides.London.Uint64() != 50 {
+		t.Fatalf("configured overrides modified")
+	}
+}
@@ -26,14 +26,25 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		NoPruning               bool
 		NoPrefetch              bool
 		TxLookupLimit           uint64                 `toml:",omitempty"`
+		AddressTxIndex          bool                   `toml:",omitempty"`
+		BloomBitsBlocks         uint64                 `toml:",omitempty"`
+		BloomConfirms           uint64                 `toml:",omitempty"`
 		Whitelist               map[uint64]common.Hash `toml:"-"`
+		BadBlockReportURL       string                 `toml:",omitempty"`
 		LightServ               int                    `toml:",omitempty"`
 		LightIngress            int                    `toml:",omitempty"`
 		LightEgress             int                    `toml:",omitempty"`
 		LightPeers              int                    `toml:",omitempty"`
+		LightPeersPerIP         int                    `toml:",omitempty"`
 		LightNoPrune            bool                   `toml:",omitempty"`
 		LightNoSyncServe        bool                   `toml:",omitempty"`
 		SyncFromCheckpoint      bool                   `toml:",omitempty"`
+		LightSoftTimeout        time.Duration          `toml:",omitempty"`
+		LightHardTimeout        time.Duration          `toml:",omitempty"`
+		LightTxStatusRetries    int                    `toml:",omitempty"`
+		LightTxStatusQuorum     int                    `toml:",omitempty"`
+		LightStateHistory       uint64                 `toml:",omitempty"`
+		LightPriorityClients    []string               `toml:",omitempty"`
 		UltraLightServers       []string               `toml:",omitempty"`
 		UltraLightFraction      int                    `toml:",omitempty"`
 		UltraLightOnlyAnnounce  bool                   `toml:",omitempty"`
@@ -53,11 +64,13 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
+		EnableOpcodeMetrics     bool
 		DocRoot                 string `toml:"-"`
 		RPCGasCap               uint64
 		RPCTxFeeCap             float64
 		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
+		Overrides               *core.ChainOverrides           `toml:",omitempty"`
 		OverrideLondon          *big.Int                       `toml:",omitempty"`
 	}
 	var enc Config
@@ -69,14 +82,25 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
+	enc.AddressTxIndex = c.AddressTxIndex
+	enc.BloomBitsBlocks = c.BloomBitsBlocks
+	enc.BloomConfirms = c.BloomConfirms
 	enc.Whitelist = c.Whitelist
+	enc.BadBlockReportURL = c.BadBlockReportURL
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
 	enc.LightEgress = c.LightEgress
 	enc.LightPeers = c.LightPeers
+	enc.LightPeersPerIP = c.LightPeersPerIP
 	enc.LightNoPrune = c.LightNoPrune
 	enc.LightNoSyncServe = c.LightNoSyncServe
 	enc.SyncFromCheckpoint = c.SyncFromCheckpoint
+	enc.LightSoftTimeout = c.LightSoftTimeout
+	enc.LightHardTimeout = c.LightHardTimeout
+	enc.LightTxStatusRetries = c.LightTxStatusRetries
+	enc.LightTxStatusQuorum = c.LightTxStatusQuorum
+	enc.LightStateHistory = c.LightStateHistory
+	enc.LightPriorityClients = c.LightPriorityClients
 	enc.UltraLightServers = c.UltraLightServers
 	enc.UltraLightFraction = c.UltraLightFraction
 	enc.UltraLightOnlyAnnounce = c.UltraLightOnlyAnnounce
@@ -96,11 +120,13 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.EnableOpcodeMetrics = c.EnableOpcodeMetrics
 	enc.DocRoot = c.DocRoot
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
+	enc.Overrides = c.Overrides
 	enc.OverrideLondon = c.OverrideLondon
 	return &enc, nil
 }
@@ -116,14 +142,25 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		NoPruning               *bool
 		NoPrefetch              *bool
 		TxLookupLimit           *uint64                `toml:",omitempty"`
+		AddressTxIndex          *bool                  `toml:",omitempty"`
+		BloomBitsBlocks         *uint64                `toml:",omitempty"`
+		BloomConfirms           *uint64                `toml:",omitempty"`
 		Whitelist               map[uint64]common.Hash `toml:"-"`
+		BadBlockReportURL       *string                `toml:",omitempty"`
 		LightServ               *int                   `toml:",omitempty"`
 		LightIngress            *int                   `toml:",omitempty"`
 		LightEgress             *int                   `toml:",omitempty"`
 		LightPeers              *int                   `toml:",omitempty"`
+		LightPeersPerIP         *int                   `toml:",omitempty"`
 		LightNoPrune            *bool                  `toml:",omitempty"`
 		LightNoSyncServe        *bool                  `toml:",omitempty"`
 		SyncFromCheckpoint      *bool                  `toml:",omitempty"`
+		LightSoftTimeout        *time.Duration         `toml:",omitempty"`
+		LightHardTimeout        *time.Duration         `toml:",omitempty"`
+		LightTxStatusRetries    *int                   `toml:",omitempty"`
+		LightTxStatusQuorum     *int                   `toml:",omitempty"`
+		LightStateHistory       *uint64                `toml:",omitempty"`
+		LightPriorityClients    []string               `toml:",omitempty"`
 		UltraLightServers       []string               `toml:",omitempty"`
 		UltraLightFraction      *int                   `toml:",omitempty"`
 		UltraLightOnlyAnnounce  *bool                  `toml:",omitempty"`
@@ -143,11 +180,13 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
+		EnableOpcodeMetrics     *bool
 		DocRoot                 *string `toml:"-"`
 		RPCGasCap               *uint64
 		RPCTxFeeCap             *float64
 		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
+		Overrides               *core.ChainOverrides           `toml:",omitempty"`
 		OverrideLondon          *big.Int                       `toml:",omitempty"`
 	}
 	var dec Config
@@ -178,9 +217,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
+	if dec.AddressTxIndex != nil {
+		c.AddressTxIndex = *dec.AddressTxIndex
+	}
+	if dec.BloomBitsBlocks != nil {
+		c.BloomBitsBlocks = *dec.BloomBitsBlocks
+	}
+	if dec.BloomConfirms != nil {
+		c.BloomConfirms = *dec.BloomConfirms
+	}
 	if dec.Whitelist != nil {
 		c.Whitelist = dec.Whitelist
 	}
+	if dec.BadBlockReportURL != nil {
+		c.BadBlockReportURL = *dec.BadBlockReportURL
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
@@ -193,6 +244,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.LightPeers != nil {
 		c.LightPeers = *dec.LightPeers
 	}
+	if dec.LightPeersPerIP != nil {
+		c.LightPeersPerIP = *dec.LightPeersPerIP
+	}
 	if dec.LightNoPrune != nil {
 		c.LightNoPrune = *dec.LightNoPrune
 	}
@@ -202,6 +256,24 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncFromCheckpoint != nil {
 		c.SyncFromCheckpoint = *dec.SyncFromCheckpoint
 	}
+	if dec.LightSoftTimeout != nil {
+		c.LightSoftTimeout = *dec.LightSoftTimeout
+	}
+	if dec.LightHardTimeout != nil {
+		c.LightHardTimeout = *dec.LightHardTimeout
+	}
+	if dec.LightTxStatusRetries != nil {
+		c.LightTxStatusRetries = *dec.LightTxStatusRetries
+	}
+	if dec.LightTxStatusQuorum != nil {
+		c.LightTxStatusQuorum = *dec.LightTxStatusQuorum
+	}
+	if dec.LightStateHistory != nil {
+		c.LightStateHistory = *dec.LightStateHistory
+	}
+	if dec.LightPriorityClients != nil {
+		c.LightPriorityClients = dec.LightPriorityClients
+	}
 	if dec.UltraLightServers != nil {
 		c.UltraLightServers = dec.UltraLightServers
 	}
@@ -259,6 +331,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.EnableOpcodeMetrics != nil {
+		c.EnableOpcodeMetrics = *dec.EnableOpcodeMetrics
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
@@ -274,6 +349,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.CheckpointOracle != nil {
 		c.CheckpointOracle = dec.CheckpointOracle
 	}
+	if dec.Overrides != nil {
+		c.Overrides = dec.Overrides
+	}
 	if dec.OverrideLondon != nil {
 		c.OverrideLondon = dec.OverrideLondon
 	}
@@ -792,16 +792,19 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *Contex
 				return nil, err
 			}
 		}
-		// Constuct the JavaScript tracer to execute with
-		if tracer, err = New(*config.Tracer, txctx); err != nil {
+		// Construct the native or JavaScript tracer to execute with
+		t, err := newTracer(*config.Tracer, txctx)
+		if err != nil {
 			return nil, err
 		}
+		tracer = t
+
 		// Handle timeouts and RPC cancellations
 		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
 		go func() {
 			<-deadlineCtx.Done()
 			if deadlineCtx.Err() == context.DeadlineExceeded {
-				tracer.(*Tracer).Stop(errors.New("execution timeout"))
+				t.Stop(errors.New("execution timeout"))
 			}
 		}()
 		defer cancel()
@@ -838,7 +841,7 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *Contex
 			StructLogs:  ethapi.FormatLogs(tracer.StructLogs()),
 		}, nil
 
-	case *Tracer:
+	case resultTracer:
 		return tracer.GetResult()
 
 	default:
@@ -353,6 +353,7 @@ func TestOverridenTraceCall(t *testing.T) {
 				Type:    "CALL",
 				From:    randomAccounts[0].addr,
 				To:      randomAccounts[1].addr,
+				Input:   newRPCBytes([]byte{}),
 				Gas:     newRPCUint64(24979000),
 				GasUsed: newRPCUint64(0),
 				Value:   (*hexutil.Big)(big.NewInt(1000)),
@@ -409,7 +410,7 @@ func TestOverridenTraceCall(t *testing.T) {
 				Type:    "CALL",
 				From:    randomAccounts[0].addr,
 				To:      randomAccounts[2].addr,
-				Input:   hexutil.Bytes(common.Hex2Bytes("8381f58a")),
+				Input:   newRPCBytes(common.Hex2Bytes("8381f58a")),
 				Output:  hexutil.Bytes(common.BigToHash(big.NewInt(123)).Bytes()),
 				Gas:     newRPCUint64(24978936),
 				GasUsed: newRPCUint64(2283),
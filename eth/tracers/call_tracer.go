@@ -0,0 +1,294 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// callFrame is a single call of the transaction, along with all the internal
+// calls it made, as reported by the call tracer.
+type callFrame struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      *common.Address `json:"to,omitempty"`
+	Value   *hexutil.Big    `json:"value,omitempty"`
+	Gas     *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Input   *hexutil.Bytes  `json:"input,omitempty"`
+	Output  hexutil.Bytes   `json:"output,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Time    string          `json:"time,omitempty"`
+	Calls   []callFrame     `json:"calls,omitempty"`
+
+	gasIn   uint64      // Gas available before the call opcode
+	gasCost uint64      // Gas cost of the call opcode
+	outOff  uint256.Int // Memory offset of the call's return data
+	outLen  uint256.Int // Memory length of the call's return data
+}
+
+// callTracer is a Go implementation of the callTracer JavaScript tracer, which
+// extracts and reports all the internal calls made by a transaction as a tree
+// of call frames.
+type callTracer struct {
+	env         *vm.EVM
+	callstack   []*callFrame                // Current recursive call stack of the EVM execution
+	descended   bool                        // Whether we've just descended into an inner call
+	precompiles map[common.Address]struct{} // Precompiled contracts active in the traced block
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+	err       error  // Error raised while tracing, if any
+}
+
+// newCallTracer creates a new native call tracer.
+func newCallTracer() *callTracer {
+	return &callTracer{
+		callstack:   []*callFrame{{}},
+		precompiles: make(map[common.Address]struct{}),
+	}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+
+	root := t.callstack[0]
+	root.Type = "CALL"
+	if create {
+		root.Type = "CREATE"
+	}
+	root.From, root.To = from, &to
+	input = common.CopyBytes(input)
+	root.Input = (*hexutil.Bytes)(&input)
+	root.Gas = (*hexutil.Uint64)(&gas)
+	if value != nil {
+		root.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	} else {
+		root.Value = new(hexutil.Big)
+	}
+	for _, addr := range vm.ActivePrecompiles(env.ChainConfig().Rules(env.Context.BlockNumber)) {
+		t.precompiles[addr] = struct{}{}
+	}
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	// If tracing was interrupted, set the error and abort the execution
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+		env.Cancel()
+		return
+	}
+	// Capture any errors immediately
+	if err != nil {
+		t.CaptureFault(env, pc, op, gas, cost, scope, depth, err)
+		return
+	}
+	stack := scope.Stack
+
+	switch op {
+	case vm.CREATE, vm.CREATE2:
+		// If a new contract is being created, add to the call stack
+		input := memorySlice(scope.Memory, stack.Back(1), stack.Back(2))
+		t.callstack = append(t.callstack, &callFrame{
+			Type:    op.String(),
+			From:    scope.Contract.Address(),
+			Input:   (*hexutil.Bytes)(&input),
+			Value:   (*hexutil.Big)(stack.Back(0).ToBig()),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return
+
+	case vm.SELFDESTRUCT:
+		// If a contract is being self destructed, gather that as a subcall too.
+		// Its input is left nil to be omitted, same as the JavaScript tracer.
+		var (
+			from = scope.Contract.Address()
+			to   = common.Address(stack.Back(0).Bytes20())
+		)
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, callFrame{
+			Type:  op.String(),
+			From:  from,
+			To:    &to,
+			Value: (*hexutil.Big)(new(big.Int).Set(env.StateDB.GetBalance(from))),
+		})
+		return
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		// Skip any pre-compile invocations, those are just fancy opcodes
+		to := common.Address(stack.Back(1).Bytes20())
+		if _, ok := t.precompiles[to]; ok {
+			return
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		input := memorySlice(scope.Memory, stack.Back(2+off), stack.Back(3+off))
+		call := &callFrame{
+			Type:    op.String(),
+			From:    scope.Contract.Address(),
+			To:      &to,
+			Input:   (*hexutil.Bytes)(&input),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  *stack.Back(4 + off),
+			outLen:  *stack.Back(5 + off),
+		}
+		if off == 1 {
+			call.Value = (*hexutil.Big)(stack.Back(2).ToBig())
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return
+	}
+	// If we've just descended into an inner call, retrieve its true allowance. It
+	// needs to be extracted from within the call as there may be funky gas dynamics
+	// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+	// Calls made to plain accounts never execute any code, so their gas is unknown.
+	if t.descended {
+		if depth >= len(t.callstack) {
+			t.callstack[len(t.callstack)-1].Gas = (*hexutil.Uint64)(&gas)
+		}
+		t.descended = false
+	}
+	if op == vm.REVERT {
+		t.callstack[len(t.callstack)-1].Error = "execution reverted"
+		return
+	}
+	if depth != len(t.callstack)-1 {
+		return
+	}
+	// An inner call returned, pop it off the call stack and get the execution results
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+
+	ret := stack.Back(0)
+	if call.Type == vm.CREATE.String() || call.Type == vm.CREATE2.String() {
+		// If the call was a CREATE, retrieve the contract address and output code
+		gasUsed := call.gasIn - call.gasCost - gas
+		call.GasUsed = (*hexutil.Uint64)(&gasUsed)
+
+		if !ret.IsZero() {
+			addr := common.Address(ret.Bytes20())
+			call.To = &addr
+			call.Output = env.StateDB.GetCode(addr)
+		} else if call.Error == "" {
+			call.Error = "internal failure"
+		}
+	} else {
+		// If the call was a contract call, retrieve the gas usage and output
+		if call.Gas != nil {
+			gasUsed := call.gasIn - call.gasCost + uint64(*call.Gas) - gas
+			call.GasUsed = (*hexutil.Uint64)(&gasUsed)
+		}
+		if !ret.IsZero() {
+			call.Output = memorySlice(scope.Memory, &call.outOff, &call.outLen)
+		} else if call.Error == "" {
+			call.Error = "internal failure"
+		}
+	}
+	parent := t.callstack[len(t.callstack)-1]
+	parent.Calls = append(parent.Calls, *call)
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	// If the topmost call already reverted, don't handle the additional fault again
+	if t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	// Pop off the just failed call, consuming all of its gas
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+
+	call.Error = err.Error()
+	if call.Gas != nil {
+		call.GasUsed = call.Gas
+	}
+	// Flatten the failed call into its parent, unless it was the last one
+	if len(t.callstack) > 0 {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, *call)
+		return
+	}
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) {
+	root := t.callstack[0]
+	root.GasUsed = (*hexutil.Uint64)(&gasUsed)
+	root.Output = common.CopyBytes(output)
+	root.Time = d.String()
+
+	if root.Error == "" && err != nil {
+		root.Error = err.Error()
+	}
+	if root.Error != "" && (root.Error != "execution reverted" || len(root.Output) == 0) {
+		root.Output = nil
+	}
+}
+
+// GetResult returns the call frame tree of the traced transaction, or any error
+// accumulated during tracing.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return json.Marshal(t.callstack[0])
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// memorySlice copies a chunk of the EVM memory, returning nil if the requested
+// range is out of bounds.
+func memorySlice(mem *vm.Memory, offset, size *uint256.Int) []byte {
+	if size.IsZero() {
+		return []byte{}
+	}
+	if !offset.IsUint64() || !size.IsUint64() {
+		return nil
+	}
+	begin, length := offset.Uint64(), size.Uint64()
+	if end := begin + length; end < begin || uint64(mem.Len()) < end {
+		return nil
+	}
+	return mem.GetCopy(int64(begin), int64(length))
+}
@@ -18,15 +18,31 @@
 package tracers
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers/internal/tracers"
 )
 
 // all contains all the built in JavaScript tracers by name.
 var all = make(map[string]string)
 
+// native contains the constructors of the built in Go tracers by name. These
+// take precedence over the JavaScript tracers of the same name.
+var native = map[string]func() resultTracer{
+	"callTracer": func() resultTracer { return newCallTracer() },
+}
+
+// resultTracer is a transaction tracer producing a JSON result, which can be
+// interrupted if execution takes too long.
+type resultTracer interface {
+	vm.Tracer
+	GetResult() (json.RawMessage, error)
+	Stop(err error)
+}
+
 // camel converts a snake cased input string into a camel cased output.
 func camel(str string) string {
 	pieces := strings.Split(str, "_")
@@ -51,3 +67,12 @@ func tracer(name string) (string, bool) {
 	}
 	return "", false
 }
+
+// newTracer creates the built in Go tracer of the given name if there is one,
+// falling back to interpreting the code as a JavaScript tracer otherwise.
+func newTracer(code string, ctx *Context) (resultTracer, error) {
+	if constructor, ok := native[code]; ok {
+		return constructor(), nil
+	}
+	return New(code, ctx)
+}
@@ -95,7 +95,7 @@ type callTrace struct {
 	Type    string          `json:"type"`
 	From    common.Address  `json:"from"`
 	To      common.Address  `json:"to"`
-	Input   hexutil.Bytes   `json:"input"`
+	Input   *hexutil.Bytes  `json:"input,omitempty"`
 	Output  hexutil.Bytes   `json:"output"`
 	Gas     *hexutil.Uint64 `json:"gas,omitempty"`
 	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
@@ -204,6 +204,15 @@ func TestPrestateTracerCreate2(t *testing.T) {
 // Iterates over all the input-output datasets in the tracer test harness and
 // runs the JavaScript tracers against them.
 func TestCallTracer(t *testing.T) {
+	testCallTracer(t, func() (resultTracer, error) { return New("callTracer", new(Context)) })
+}
+
+// Tests that the native call tracer produces the same traces as the JavaScript one.
+func TestCallTracerNative(t *testing.T) {
+	testCallTracer(t, func() (resultTracer, error) { return newCallTracer(), nil })
+}
+
+func testCallTracer(t *testing.T, newTracer func() (resultTracer, error)) {
 	files, err := ioutil.ReadDir("testdata")
 	if err != nil {
 		t.Fatalf("failed to retrieve tracer test suite: %v", err)
@@ -248,7 +257,7 @@ func TestCallTracer(t *testing.T) {
 			_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), test.Genesis.Alloc, false)
 
 			// Create the tracer, the EVM environment and run it
-			tracer, err := New("callTracer", new(Context))
+			tracer, err := newTracer()
 			if err != nil {
 				t.Fatalf("failed to create call tracer: %v", err)
 			}
@@ -218,12 +218,30 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'compactTxJournal',
+			call: 'admin_compactTxJournal'
+		}),
+		new web3._extend.Method({
+			name: 'setTxPoolQuotas',
+			call: 'admin_setTxPoolQuotas',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeTx',
+			call: 'admin_removeTx',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
 			name: 'nodeInfo',
 			getter: 'admin_nodeInfo'
 		}),
+		new web3._extend.Property({
+			name: 'txPoolQuotas',
+			getter: 'admin_txPoolQuotas'
+		}),
 		new web3._extend.Property({
 			name: 'peers',
 			getter: 'admin_peers'
@@ -279,6 +297,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'dumpBlockPage',
+			call: 'debug_dumpBlock',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'chaindbProperty',
 			call: 'debug_chaindbProperty',
@@ -460,6 +484,16 @@ web3._extend({
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'opcodeStats',
+			call: 'debug_opcodeStats',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'resetOpcodeStats',
+			call: 'debug_resetOpcodeStats',
+			params: 0,
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
@@ -561,6 +595,12 @@ web3._extend({
 			call: 'eth_getRawTransactionByHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getTransactionsByAddress',
+			call: 'eth_getTransactionsByAddress',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransactionFromBlock',
 			call: function(args) {
@@ -19,6 +19,7 @@ package les
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -131,9 +132,14 @@ func (api *PrivateLightServerAPI) clientInfo(peer *clientPeer, balance vfs.ReadO
 	//		cb := api.server.clientPool.ndb.getCurrencyBalance(id)
 	//		info["pricing/currency"] = cb.amount
 	if peer != nil {
+		fcParams := peer.getFlowControlParams()
 		info["connectionTime"] = float64(mclock.Now()-peer.connectedAt) / float64(time.Second)
 		info["capacity"] = peer.getCapacity()
 		info["pricing/negBalance"] = nb
+		info["version"] = peer.version
+		info["flowControl/BL"] = fcParams.BufLimit
+		info["flowControl/MRR"] = fcParams.MinRecharge
+		info["servedRequests"] = atomic.LoadUint64(&peer.servedCount)
 	}
 	return info
 }
@@ -80,6 +80,18 @@ type LightEthereum struct {
 
 // New creates an instance of the light client.
 func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
+	if config.LightTxStatusRetries < 0 {
+		return nil, fmt.Errorf("invalid transaction status retries %d", config.LightTxStatusRetries)
+	}
+	if config.LightTxStatusQuorum < 0 {
+		return nil, fmt.Errorf("invalid transaction status quorum %d", config.LightTxStatusQuorum)
+	}
+	if config.LightSoftTimeout < 0 {
+		return nil, fmt.Errorf("invalid soft request timeout %v", config.LightSoftTimeout)
+	}
+	if config.LightHardTimeout < 0 {
+		return nil, fmt.Errorf("invalid hard request timeout %v", config.LightHardTimeout)
+	}
 	chainDb, err := stack.OpenDatabase("lightchaindata", config.DatabaseCache, config.DatabaseHandles, "eth/db/chaindata/", false)
 	if err != nil {
 		return nil, err
@@ -88,7 +100,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideLondon)
+	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.ChainOverrides())
 	if _, isCompat := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !isCompat {
 		return nil, genesisErr
 	}
@@ -124,10 +136,20 @@ func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
 	leth.serverPool, leth.serverPoolIterator = vfc.NewServerPool(lesDb, []byte("serverpool:"), time.Second, prenegQuery, &mclock.System{}, config.UltraLightServers, requestList)
 	leth.serverPool.AddMetrics(suggestedTimeoutGauge, totalValueGauge, serverSelectableGauge, serverConnectedGauge, sessionValueMeter, serverDialedMeter)
 
-	leth.retriever = newRetrieveManager(peers, leth.reqDist, leth.serverPool.GetTimeout)
-	leth.relay = newLesTxRelay(peers, leth.retriever)
+	srto := leth.serverPool.GetTimeout
+	if config.LightSoftTimeout != 0 {
+		srto = func() time.Duration { return config.LightSoftTimeout }
+	}
+	leth.retriever = newRetrieveManager(peers, leth.reqDist, srto, config.LightHardTimeout)
+	leth.relay = newLesTxRelay(peers, leth.retriever, &mclock.System{})
 
 	leth.odr = NewLesOdr(chainDb, light.DefaultClientIndexerConfig, leth.peers, leth.retriever)
+	if config.LightTxStatusRetries != 0 {
+		leth.odr.SetTxStatusRetries(config.LightTxStatusRetries)
+	}
+	if config.LightTxStatusQuorum != 0 {
+		leth.odr.SetTxStatusQuorum(config.LightTxStatusQuorum)
+	}
 	leth.chtIndexer = light.NewChtIndexer(chainDb, leth.odr, params.CHTFrequency, params.HelperTrieConfirmations, config.LightNoPrune)
 	leth.bloomTrieIndexer = light.NewBloomTrieIndexer(chainDb, leth.odr, params.BloomBitsBlocksClient, params.BloomTrieFrequency, config.LightNoPrune)
 	leth.odr.SetIndexers(leth.chtIndexer, leth.bloomTrieIndexer, leth.bloomIndexer)
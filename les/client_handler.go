@@ -64,10 +64,11 @@ func newClientHandler(ulcServers []string, ulcFraction int, checkpoint *params.T
 	if ulcServers != nil {
 		ulc, err := newULC(ulcServers, ulcFraction)
 		if err != nil {
-			log.Error("Failed to initialize ultra light client")
+			log.Error("Failed to initialize ultra light client", "err", err)
+		} else {
+			handler.ulc = ulc
+			log.Info("Enable ultra light client mode")
 		}
-		handler.ulc = ulc
-		log.Info("Enable ultra light client mode")
 	}
 	var height uint64
 	if checkpoint != nil {
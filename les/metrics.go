@@ -99,8 +99,19 @@ var (
 	clientFreezeMeter = metrics.NewRegisteredMeter("les/server/clientEvent/freeze", nil)
 	clientErrorMeter  = metrics.NewRegisteredMeter("les/server/clientEvent/error", nil)
 
-	requestRTT       = metrics.NewRegisteredTimer("les/client/req/rtt", nil)
-	requestSendDelay = metrics.NewRegisteredTimer("les/client/req/sendDelay", nil)
+	requestRTT              = metrics.NewRegisteredTimer("les/client/req/rtt", nil)
+	requestSendDelay        = metrics.NewRegisteredTimer("les/client/req/sendDelay", nil)
+	requestSoftTimeoutMeter = metrics.NewRegisteredMeter("les/client/req/timeout/soft", nil)
+	requestHardTimeoutMeter = metrics.NewRegisteredMeter("les/client/req/timeout/hard", nil)
+
+	odrBodyMeters       = newOdrRequestMeters("body")
+	odrReceiptMeters    = newOdrRequestMeters("receipt")
+	odrProofMeters      = newOdrRequestMeters("proof")
+	odrCodeMeters       = newOdrRequestMeters("code")
+	odrChtMeters        = newOdrRequestMeters("cht")
+	odrBloomMeters      = newOdrRequestMeters("bloomBits")
+	odrTxStatusMeters   = newOdrRequestMeters("txStatus")
+	odrUnknownReqMeters = newOdrRequestMeters("unknown")
 
 	serverSelectableGauge = metrics.NewRegisteredGauge("les/client/serverPool/selectable", nil)
 	serverDialedMeter     = metrics.NewRegisteredMeter("les/client/serverPool/dialed", nil)
@@ -110,6 +121,43 @@ var (
 	suggestedTimeoutGauge = metrics.NewRegisteredGauge("les/client/serverPool/timeout", nil)
 )
 
+// odrRequestMeters contains the client side metrics of a specific ODR request type.
+type odrRequestMeters struct {
+	sent   metrics.Meter // Number of retrievals started
+	failed metrics.Meter // Number of retrievals failed, timed out or cancelled
+	rtt    metrics.Timer // Round trip time of the successful retrievals
+}
+
+func newOdrRequestMeters(name string) *odrRequestMeters {
+	return &odrRequestMeters{
+		sent:   metrics.NewRegisteredMeter("les/client/req/"+name+"/sent", nil),
+		failed: metrics.NewRegisteredMeter("les/client/req/"+name+"/failed", nil),
+		rtt:    metrics.NewRegisteredTimer("les/client/req/"+name+"/rtt", nil),
+	}
+}
+
+// odrMeters returns the metrics belonging to the type of the given request.
+func odrMeters(req LesOdrRequest) *odrRequestMeters {
+	switch req.(type) {
+	case *BlockRequest:
+		return odrBodyMeters
+	case *ReceiptsRequest:
+		return odrReceiptMeters
+	case *TrieRequest, *TrieBatchRequest:
+		return odrProofMeters
+	case *CodeRequest:
+		return odrCodeMeters
+	case *ChtRequest:
+		return odrChtMeters
+	case *BloomRequest:
+		return odrBloomMeters
+	case *TxStatusRequest:
+		return odrTxStatusMeters
+	default:
+		return odrUnknownReqMeters
+	}
+}
+
 // meteredMsgReadWriter is a wrapper around a p2p.MsgReadWriter, capable of
 // accumulating the above defined metrics based on the data stream contents.
 type meteredMsgReadWriter struct {
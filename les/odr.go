@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/light"
 )
@@ -35,16 +36,19 @@ type LesOdr struct {
 	chtIndexer, bloomTrieIndexer, bloomIndexer *core.ChainIndexer
 	peers                                      *serverPeerSet
 	retriever                                  *retrieveManager
+	txStatusRetries                            int
+	txStatusQuorum                             int
 	stop                                       chan struct{}
 }
 
 func NewLesOdr(db ethdb.Database, config *light.IndexerConfig, peers *serverPeerSet, retriever *retrieveManager) *LesOdr {
 	return &LesOdr{
-		db:            db,
-		indexerConfig: config,
-		peers:         peers,
-		retriever:     retriever,
-		stop:          make(chan struct{}),
+		db:              db,
+		indexerConfig:   config,
+		peers:           peers,
+		retriever:       retriever,
+		txStatusRetries: maxTxStatusRetry,
+		stop:            make(chan struct{}),
 	}
 }
 
@@ -65,6 +69,18 @@ func (odr *LesOdr) SetIndexers(chtIndexer, bloomTrieIndexer, bloomIndexer *core.
 	odr.bloomIndexer = bloomIndexer
 }
 
+// SetTxStatusRetries sets the maximum number of retries made for retrieving
+// an unknown transaction status.
+func (odr *LesOdr) SetTxStatusRetries(retries int) {
+	odr.txStatusRetries = retries
+}
+
+// SetTxStatusQuorum sets the number of servers required to report the same
+// transaction status, unless a request specifies its own quorum.
+func (odr *LesOdr) SetTxStatusQuorum(quorum int) {
+	odr.txStatusQuorum = quorum
+}
+
 // ChtIndexer returns the CHT chain indexer
 func (odr *LesOdr) ChtIndexer() *core.ChainIndexer {
 	return odr.chtIndexer
@@ -119,7 +135,7 @@ func (h peerByTxHistory) Less(i, j int) bool {
 func (h peerByTxHistory) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
 
 const (
-	maxTxStatusRetry      = 3 // The maximum retrys will be made for tx status request.
+	maxTxStatusRetry      = 3 // The default maximum retrys will be made for tx status request.
 	maxTxStatusCandidates = 5 // The maximum les servers the tx status requests will be sent to.
 )
 
@@ -129,16 +145,29 @@ const (
 // is unindexed, the malicous server doesn't reply it deliberately, etc).
 // Therefore, unretrieved transactions(UNKNOWN) will receive a certain number
 // of retries, thus giving a weak guarantee.
+//
+// If a quorum is required by the request or the client configuration, a status
+// is only accepted once the given number of servers reported it. Transactions
+// which servers report at conflicting inclusion positions are failed with the
+// ErrTxStatusMismatch error in their status.
 func (odr *LesOdr) RetrieveTxStatus(ctx context.Context, req *light.TxStatusRequest) error {
 	// Sort according to the transaction history supported by the peer and
 	// select the peers with longest history.
 	var (
 		retries int
 		peers   []*serverPeer
+		quorum  = odr.txStatusQuorum
 		missing = len(req.Hashes)
 		result  = make([]light.TxStatus, len(req.Hashes))
+		votes   = make([]txStatusVote, len(req.Hashes))
 		canSend = make(map[string]bool)
 	)
+	if req.Quorum != 0 {
+		quorum = req.Quorum
+	}
+	if quorum < 1 {
+		quorum = 1
+	}
 	for _, peer := range odr.peers.allPeers() {
 		if peer.txHistory == txIndexDisabled {
 			continue
@@ -146,12 +175,12 @@ func (odr *LesOdr) RetrieveTxStatus(ctx context.Context, req *light.TxStatusRequ
 		peers = append(peers, peer)
 	}
 	sort.Sort(sort.Reverse(peerByTxHistory(peers)))
-	for i := 0; i < maxTxStatusCandidates && i < len(peers); i++ {
+	for i := 0; (i < maxTxStatusCandidates || i < quorum) && i < len(peers); i++ {
 		canSend[peers[i].id] = true
 	}
 	// Send out the request and assemble the result.
 	for {
-		if retries >= maxTxStatusRetry || len(canSend) == 0 {
+		if retries >= odr.txStatusRetries+quorum-1 || len(canSend) == 0 {
 			break
 		}
 		var (
@@ -169,20 +198,33 @@ func (odr *LesOdr) RetrieveTxStatus(ctx context.Context, req *light.TxStatusRequ
 				},
 			}
 		)
+		start := mclock.Now()
+		odrTxStatusMeters.sent.Mark(1)
 		if err := odr.retriever.retrieve(ctx, id, distreq, func(p distPeer, msg *Msg) error { return req.Validate(odr.db, msg) }, odr.stop); err != nil {
+			odrTxStatusMeters.failed.Mark(1)
 			return err
 		}
+		odrTxStatusMeters.rtt.Update(time.Duration(mclock.Now() - start))
+
 		// Collect the response and assemble them to the final result.
-		// All the response is not verifiable, so always pick the first
-		// one we get.
+		// All the response is not verifiable, so pick the first status
+		// reported by enough servers.
 		for index, status := range req.Status {
-			if result[index].Status != core.TxStatusUnknown {
+			if result[index].Status != core.TxStatusUnknown || result[index].Error != "" {
 				continue
 			}
 			if status.Status == core.TxStatusUnknown {
 				continue
 			}
-			result[index], missing = status, missing-1
+			accepted, err := addTxStatusVote(&votes[index], status, quorum)
+			if err != nil {
+				// Only fail the conflicting transaction, not the whole batch
+				result[index], missing = light.TxStatus{Error: err.Error()}, missing-1
+				continue
+			}
+			if accepted {
+				result[index], missing = status, missing-1
+			}
 		}
 		// Abort the procedure if all the status are retrieved
 		if missing == 0 {
@@ -194,6 +236,43 @@ func (odr *LesOdr) RetrieveTxStatus(ctx context.Context, req *light.TxStatusRequ
 	return nil
 }
 
+// txStatusVote is a transaction status reported by a number of servers.
+type txStatusVote struct {
+	status light.TxStatus
+	count  int
+}
+
+// addTxStatusVote adds a server reported status to the tally and returns whether
+// it reached the quorum.
+//
+// Servers propagate transactions and blocks at different paces, so a server may
+// report a transaction as pending while another one already has it included.
+// The most advanced status is kept: less advanced reports are ignored, a more
+// advanced one restarts the tally. Only two different inclusion positions are
+// a conflict, which is returned as an error.
+func addTxStatusVote(vote *txStatusVote, status light.TxStatus, quorum int) (bool, error) {
+	switch {
+	case vote.count == 0 || status.Status > vote.status.Status:
+		vote.status, vote.count = status, 1
+	case status.Status < vote.status.Status:
+		return false, nil
+	case status.Status == core.TxStatusIncluded && !sameTxLookup(vote.status.Lookup, status.Lookup):
+		return false, light.ErrTxStatusMismatch
+	default:
+		vote.count++
+	}
+	return vote.count >= quorum, nil
+}
+
+// sameTxLookup reports whether two inclusion positions of a transaction are
+// identical.
+func sameTxLookup(a, b *rawdb.LegacyTxLookupEntry) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 // Retrieve tries to fetch an object from the LES network. It's a common API
 // for most of the LES requests except for the TxStatusRequest which needs
 // the additional retry mechanism.
@@ -221,11 +300,16 @@ func (odr *LesOdr) Retrieve(ctx context.Context, req light.OdrRequest) (err erro
 		},
 	}
 
+	meters := odrMeters(lreq)
+	meters.sent.Mark(1)
 	defer func(sent mclock.AbsTime) {
 		if err != nil {
+			meters.failed.Mark(1)
 			return
 		}
-		requestRTT.Update(time.Duration(mclock.Now() - sent))
+		rtt := time.Duration(mclock.Now() - sent)
+		requestRTT.Update(rtt)
+		meters.rtt.Update(rtt)
 	}(mclock.Now())
 
 	if err := odr.retriever.retrieve(ctx, reqID, rq, func(p distPeer, msg *Msg) error { return lreq.Validate(odr.db, msg) }, odr.stop); err != nil {
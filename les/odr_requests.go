@@ -60,6 +60,8 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 		return (*ReceiptsRequest)(r)
 	case *light.TrieRequest:
 		return (*TrieRequest)(r)
+	case *light.TrieBatchRequest:
+		return (*TrieBatchRequest)(r)
 	case *light.CodeRequest:
 		return (*CodeRequest)(r)
 	case *light.ChtRequest:
@@ -237,6 +239,62 @@ func (r *TrieRequest) Validate(db ethdb.Database, msg *Msg) error {
 	return nil
 }
 
+// ODR request type for multiple state/storage trie entries of the same block,
+// see LesOdrRequest interface
+type TrieBatchRequest light.TrieBatchRequest
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *TrieBatchRequest) GetCost(peer *serverPeer) uint64 {
+	return peer.getRequestCost(GetProofsV2Msg, len(r.Reqs))
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *TrieBatchRequest) CanSend(peer *serverPeer) bool {
+	id := r.Reqs[0].Id
+	return peer.HasBlock(id.BlockHash, id.BlockNumber, true)
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *TrieBatchRequest) Request(reqID uint64, peer *serverPeer) error {
+	peer.Log().Debug("Requesting trie proofs", "block", r.Reqs[0].Id.BlockHash, "count", len(r.Reqs))
+	reqs := make([]ProofReq, len(r.Reqs))
+	for i, req := range r.Reqs {
+		reqs[i] = ProofReq{
+			BHash:  req.Id.BlockHash,
+			AccKey: req.Id.AccKey,
+			Key:    req.Key,
+		}
+	}
+	return peer.requestProofs(reqID, reqs)
+}
+
+// Valid processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *TrieBatchRequest) Validate(db ethdb.Database, msg *Msg) error {
+	log.Debug("Validating trie proofs", "block", r.Reqs[0].Id.BlockHash, "count", len(r.Reqs))
+
+	if msg.MsgType != MsgProofsV2 {
+		return errInvalidMessageType
+	}
+	proofs := msg.Obj.(light.NodeList)
+	// Verify all the proofs against the merged node set and store if checks out
+	nodeSet := proofs.NodeSet()
+	reads := &readTraceDB{db: nodeSet}
+	for _, req := range r.Reqs {
+		if _, err := trie.VerifyProof(req.Id.Root, req.Key, reads); err != nil {
+			return fmt.Errorf("merkle proof verification failed: %v", err)
+		}
+	}
+	// check if all nodes have been read by VerifyProof
+	if len(reads.reads) != nodeSet.KeyCount() {
+		return errUselessNodes
+	}
+	r.Proof = nodeSet
+	return nil
+}
+
 type CodeReq struct {
 	BHash  common.Hash
 	AccKey []byte
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -35,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -111,6 +113,58 @@ func odrAccounts(ctx context.Context, db ethdb.Database, config *params.ChainCon
 	return res
 }
 
+func TestOdrPrefetchStateLes2(t *testing.T) { testOdr(t, 2, 1, true, odrPrefetchState) }
+func TestOdrPrefetchStateLes3(t *testing.T) { testOdr(t, 3, 1, true, odrPrefetchState) }
+func TestOdrPrefetchStateLes4(t *testing.T) { testOdr(t, 4, 1, true, odrPrefetchState) }
+
+// localOdr is an ODR backend which fails all the retrievals, so only the data
+// already available in the local database can be accessed.
+type localOdr struct {
+	light.OdrBackend
+}
+
+func (localOdr) Retrieve(ctx context.Context, req light.OdrRequest) error {
+	return errors.New("retrieval disabled")
+}
+
+func odrPrefetchState(ctx context.Context, db ethdb.Database, config *params.ChainConfig, bc *core.BlockChain, lc *light.LightChain, bhash common.Hash) []byte {
+	dummyAddr := common.HexToAddress("1234567812345678123456781234567812345678")
+	slots := []common.Hash{common.BigToHash(big.NewInt(0)), common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))}
+	list := types.AccessList{
+		{Address: bankAddr},
+		{Address: userAddr1},
+		{Address: userAddr2},
+		{Address: dummyAddr},
+		{Address: testContractAddr, StorageKeys: slots},
+	}
+	var st *state.StateDB
+	if bc != nil {
+		header := bc.GetHeaderByHash(bhash)
+		st, _ = state.New(header.Root, state.NewDatabase(db), nil)
+	} else {
+		// Prefetch the whole access list, the state must be accessible
+		// afterwards without any further retrievals.
+		header := lc.GetHeaderByHash(bhash)
+		if err := light.PrefetchState(ctx, lc.Odr(), header, list); err != nil {
+			return nil
+		}
+		st = light.NewState(ctx, header, localOdr{lc.Odr()})
+	}
+	var res []byte
+	for _, tuple := range list {
+		bal, _ := rlp.EncodeToBytes(st.GetBalance(tuple.Address))
+		res = append(res, bal...)
+		for _, slot := range tuple.StorageKeys {
+			value := st.GetState(tuple.Address, slot)
+			res = append(res, value[:]...)
+		}
+	}
+	if st.Error() != nil {
+		return nil
+	}
+	return res
+}
+
 func TestOdrContractCallLes2(t *testing.T) { testOdr(t, 2, 2, true, odrContractCall) }
 func TestOdrContractCallLes3(t *testing.T) { testOdr(t, 3, 2, true, odrContractCall) }
 func TestOdrContractCallLes4(t *testing.T) { testOdr(t, 4, 2, true, odrContractCall) }
@@ -272,8 +326,10 @@ func testGetTxStatusFromUnindexedPeers(t *testing.T, protocol int) {
 
 	// Iterate the chain, create the tx indexes locally
 	var (
-		testHash   common.Hash
-		testStatus light.TxStatus
+		testHash    common.Hash
+		testStatus  light.TxStatus
+		otherHash   common.Hash
+		otherStatus light.TxStatus
 
 		txs          = make(map[common.Hash]*types.Transaction) // Transaction objects set
 		blockNumbers = make(map[common.Hash]uint64)             // Transaction hash to block number mappings
@@ -291,21 +347,25 @@ func testGetTxStatusFromUnindexedPeers(t *testing.T, protocol int) {
 			blockHashes[tx.Hash()] = block.Hash()
 			intraIndex[tx.Hash()] = uint64(index)
 
+			status := light.TxStatus{
+				Status: core.TxStatusIncluded,
+				Lookup: &rawdb.LegacyTxLookupEntry{
+					BlockHash:  block.Hash(),
+					BlockIndex: block.NumberU64(),
+					Index:      uint64(index),
+				},
+			}
 			if testHash == (common.Hash{}) {
-				testHash = tx.Hash()
-				testStatus = light.TxStatus{
-					Status: core.TxStatusIncluded,
-					Lookup: &rawdb.LegacyTxLookupEntry{
-						BlockHash:  block.Hash(),
-						BlockIndex: block.NumberU64(),
-						Index:      uint64(index),
-					},
-				}
+				testHash, testStatus = tx.Hash(), status
+			} else if otherHash == (common.Hash{}) {
+				otherHash, otherStatus = tx.Hash(), status
 			}
 		}
 	}
 	// serveMsg processes incoming GetTxStatusMsg and sends the response back.
-	serveMsg := func(peer *testPeer, txLookup uint64) error {
+	// If conflict is non-zero, the test transaction is reported in a block
+	// with a hash made of it.
+	serveMsg := func(peer *testPeer, txLookup uint64, conflict byte) error {
 		msg, err := peer.app.ReadMsg()
 		if err != nil {
 			return err
@@ -333,6 +393,9 @@ func testGetTxStatusFromUnindexedPeers(t *testing.T, protocol int) {
 				BlockIndex: number,
 				Index:      intraIndex[hash],
 			}
+			if conflict != 0 && hash == testHash {
+				stats[i].Lookup.BlockHash = common.Hash{conflict}
+			}
 		}
 		data, _ := rlp.EncodeToBytes(stats)
 		reply := &reply{peer.app, TxStatusMsg, r.ReqID, data}
@@ -344,6 +407,8 @@ func testGetTxStatusFromUnindexedPeers(t *testing.T, protocol int) {
 		peers     int
 		txLookups []uint64
 		txs       []common.Hash
+		quorum    int
+		conflict  bool
 		results   []light.TxStatus
 	}{
 		// Retrieve mined transaction from the empty peerset
@@ -388,6 +453,32 @@ func testGetTxStatusFromUnindexedPeers(t *testing.T, protocol int) {
 			txs:       []common.Hash{randomHash(), testHash},
 			results:   []light.TxStatus{{}, {}},
 		},
+		// Retrieve mined transaction requiring a quorum of the full peers
+		{
+			peers:     3,
+			txLookups: []uint64{txIndexUnlimited, txIndexUnlimited, txIndexUnlimited},
+			txs:       []common.Hash{testHash},
+			quorum:    2,
+			results:   []light.TxStatus{testStatus},
+		},
+		// Retrieve mined transaction requiring a quorum, but only one peer has it
+		{
+			peers:     3,
+			txLookups: []uint64{txIndexUnlimited, txIndexDisabled + 1, txIndexDisabled + 1},
+			txs:       []common.Hash{testHash},
+			quorum:    2,
+			results:   []light.TxStatus{{}},
+		},
+		// Retrieve transactions requiring a quorum, with the peers disagreeing on
+		// the inclusion position of one of them
+		{
+			peers:     3,
+			txLookups: []uint64{txIndexUnlimited, txIndexUnlimited, txIndexUnlimited},
+			txs:       []common.Hash{testHash, otherHash},
+			quorum:    2,
+			conflict:  true,
+			results:   []light.TxStatus{{Error: light.ErrTxStatusMismatch.Error()}, otherStatus},
+		},
 	}
 	for _, testspec := range testspecs {
 		// Create a bunch of server peers with different tx history
@@ -401,13 +492,18 @@ func testGetTxStatusFromUnindexedPeers(t *testing.T, protocol int) {
 			closeFns = append(closeFns, closePeer)
 
 			// Create a one-time routine for serving message
+			var conflict byte
+			if testspec.conflict {
+				conflict = byte(i + 1)
+			}
 			go func(i int, peer *testPeer) {
-				serveMsg(peer, testspec.txLookups[i])
+				serveMsg(peer, testspec.txLookups[i], conflict)
 			}(i, peer)
 		}
 
 		// Send out the GetTxStatus requests, compare the result with
 		// expected value.
+		client.handler.backend.odr.SetTxStatusQuorum(testspec.quorum)
 		r := &light.TxStatusRequest{Hashes: testspec.txs}
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
@@ -436,3 +532,112 @@ func randomHash() common.Hash {
 	}
 	return hash
 }
+
+func TestTxStatusQuorum(t *testing.T) {
+	var (
+		pending   = light.TxStatus{Status: core.TxStatusPending}
+		included  = light.TxStatus{Status: core.TxStatusIncluded, Lookup: &rawdb.LegacyTxLookupEntry{BlockHash: common.Hash{1}, BlockIndex: 1}}
+		reordered = light.TxStatus{Status: core.TxStatusIncluded, Lookup: &rawdb.LegacyTxLookupEntry{BlockHash: common.Hash{2}, BlockIndex: 1}}
+	)
+	// Without quorum the first response is accepted
+	var vote txStatusVote
+	if ok, err := addTxStatusVote(&vote, included, 1); !ok || err != nil {
+		t.Fatalf("first vote not accepted without quorum: %v %v", ok, err)
+	}
+	// With quorum, the status is accepted once enough servers agree
+	vote = txStatusVote{}
+	for i := 0; i < 3; i++ {
+		ok, err := addTxStatusVote(&vote, included, 3)
+		if err != nil {
+			t.Fatalf("vote %d: unexpected error: %v", i, err)
+		}
+		if ok != (i == 2) {
+			t.Fatalf("vote %d: accepted mismatch: have %v", i, ok)
+		}
+	}
+	// Servers lagging behind are ignored, the most advanced status is counted
+	for i, votes := range [][]light.TxStatus{{pending, included, included}, {included, pending, included}} {
+		vote = txStatusVote{}
+		for j, status := range votes {
+			ok, err := addTxStatusVote(&vote, status, 2)
+			if err != nil {
+				t.Fatalf("race %d, vote %d: unexpected error: %v", i, j, err)
+			}
+			if ok != (j == 2) {
+				t.Fatalf("race %d, vote %d: accepted mismatch: have %v", i, j, ok)
+			}
+		}
+		if vote.status.Status != core.TxStatusIncluded {
+			t.Errorf("race %d: status mismatch: have %v, want %v", i, vote.status.Status, core.TxStatusIncluded)
+		}
+	}
+	// Conflicting inclusion positions are rejected
+	vote = txStatusVote{}
+	if _, err := addTxStatusVote(&vote, included, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := addTxStatusVote(&vote, reordered, 2); err != light.ErrTxStatusMismatch {
+		t.Errorf("mismatch not detected: %v", err)
+	}
+}
+
+// Tests that ODR retrievals are metered by their request type, both when they
+// succeed and when they fail.
+func TestOdrRequestMeters(t *testing.T) {
+	// Swap in forcibly enabled meters for block body requests
+	defer func(enabled bool, meters *odrRequestMeters) {
+		metrics.Enabled, odrBodyMeters = enabled, meters
+	}(metrics.Enabled, odrBodyMeters)
+	metrics.Enabled = true
+	odrBodyMeters = &odrRequestMeters{sent: metrics.NewMeter(), failed: metrics.NewMeter(), rtt: metrics.NewTimer()}
+	metrics.Enabled = false
+
+	netconfig := testnetConfig{
+		blocks:    4,
+		protocol:  lpv4,
+		connect:   true,
+		nopruning: true,
+	}
+	server, client, tearDown := newClientServerEnv(t, netconfig)
+	defer tearDown()
+	waitForPeers = 0
+
+	retrieve := func(number uint64) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		hash := rawdb.ReadCanonicalHash(server.db, number)
+		_, err := light.GetBody(ctx, client.handler.backend.odr, hash, number)
+		return err
+	}
+	check := func(sent, failed, rtt int64) {
+		t.Helper()
+
+		if have := odrBodyMeters.sent.Count(); have != sent {
+			t.Errorf("sent meter mismatch: have %d, want %d", have, sent)
+		}
+		if have := odrBodyMeters.failed.Count(); have != failed {
+			t.Errorf("failed meter mismatch: have %d, want %d", have, failed)
+		}
+		if have := odrBodyMeters.rtt.Count(); have != rtt {
+			t.Errorf("rtt timer mismatch: have %d, want %d", have, rtt)
+		}
+	}
+	// A retrieval no server can serve must be metered as failed
+	client.handler.backend.peers.lock.Lock()
+	client.peer.speer.hasBlockHook = func(common.Hash, uint64, bool) bool { return false }
+	client.handler.backend.peers.lock.Unlock()
+	if err := retrieve(1); err == nil {
+		t.Fatalf("retrieval succeeded without a serving peer")
+	}
+	check(1, 1, 0)
+
+	// A served retrieval must be metered with its round trip time
+	client.handler.backend.peers.lock.Lock()
+	client.peer.speer.hasBlockHook = func(common.Hash, uint64, bool) bool { return true }
+	client.handler.backend.peers.lock.Unlock()
+	if err := retrieve(2); err != nil {
+		t.Fatalf("failed to retrieve block body: %v", err)
+	}
+	check(2, 1, 1)
+}
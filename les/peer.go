@@ -46,6 +46,7 @@ import (
 var (
 	errClosed            = errors.New("peer set is closed")
 	errAlreadyRegistered = errors.New("peer is already registered")
+	errTooManyPeersPerIP = errors.New("too many peers from the same IP")
 	errNotRegistered     = errors.New("peer is not registered")
 )
 
@@ -757,6 +758,8 @@ func (p *serverPeer) answeredRequest(id uint64) {
 // clientPeer represents each node to which the les server is connected.
 // The node here refers to the light client.
 type clientPeer struct {
+	servedCount uint64 // Number of requests served to the client, accessed atomically. Keep first for 64-bit alignment.
+
 	peerCommons
 
 	// responseLock ensures that responses are queued in the same order as
@@ -780,6 +783,9 @@ type clientPeer struct {
 	server      bool
 	errCh       chan error
 	fcClient    *flowcontrol.ClientNode // Server side mirror token bucket.
+
+	// Test hooks
+	remoteHook func() (net.IP, bool) // Used to determine the remote IP address of the client and whether it's trusted.
 }
 
 func newClientPeer(version int, network uint64, p *p2p.Peer, rw p2p.MsgReadWriter) *clientPeer {
@@ -943,6 +949,15 @@ func (p *clientPeer) getCapacity() uint64 {
 	return p.capacity
 }
 
+// getFlowControlParams returns the flow control parameters currently assigned
+// to the client.
+func (p *clientPeer) getFlowControlParams() flowcontrol.ServerParams {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.fcParams
+}
+
 // UpdateCapacity updates the request serving capacity assigned to a given client
 // and also sends an announcement about the updated flow control parameters.
 // Note: UpdateCapacity implements vfs.clientPeer and should not block. The requested
@@ -1282,9 +1297,11 @@ func (ps *serverPeerSet) close() {
 // clientPeerSet represents the set of active client peers currently
 // participating in the Light Ethereum sub-protocol.
 type clientPeerSet struct {
-	peers  map[enode.ID]*clientPeer
-	lock   sync.RWMutex
-	closed bool
+	peers    map[enode.ID]*clientPeer
+	lock     sync.RWMutex
+	closed   bool
+	maxPerIP int                 // Maximum number of untrusted peers from the same IP, 0 if unlimited
+	priority func(enode.ID) bool // Reports whitelisted priority clients exempt from the IP limit
 
 	privateKey                   *ecdsa.PrivateKey
 	lastAnnounce, signedAnnounce announceData
@@ -1307,11 +1324,37 @@ func (ps *clientPeerSet) register(peer *clientPeer) error {
 	if _, exist := ps.peers[peer.ID()]; exist {
 		return errAlreadyRegistered
 	}
+	if ps.maxPerIP > 0 && !ps.priority(peer.ID()) {
+		if ip, trusted := peer.remote(); ip != nil && !trusted {
+			var count int
+			for _, p := range ps.peers {
+				if pip, trusted := p.remote(); !trusted && !ps.priority(p.ID()) && ip.Equal(pip) {
+					count++
+				}
+			}
+			if count >= ps.maxPerIP {
+				return errTooManyPeersPerIP
+			}
+		}
+	}
 	ps.peers[peer.ID()] = peer
 	ps.announceOrStore(peer)
 	return nil
 }
 
+// remote returns the remote IP address of the peer, or nil if it's not connected
+// through TCP, and whether it's a trusted peer.
+func (p *clientPeer) remote() (net.IP, bool) {
+	if p.remoteHook != nil {
+		return p.remoteHook()
+	}
+	var ip net.IP
+	if addr, ok := p.Peer.RemoteAddr().(*net.TCPAddr); ok {
+		ip = addr.IP
+	}
+	return ip, p.Peer.Info().Network.Trusted
+}
+
 // unregister removes a remote peer from the peer set, disabling any further
 // actions to/from that particular entity. It also initiates disconnection
 // at the networking layer.
@@ -1356,6 +1399,12 @@ func (ps *clientPeerSet) len() int {
 	return len(ps.peers)
 }
 
+// setMaxPeersPerIP limits the number of untrusted, non-priority peers accepted
+// from the same IP address. Should be called before starting the protocol handler.
+func (ps *clientPeerSet) setMaxPeersPerIP(limit int, priority func(enode.ID) bool) {
+	ps.maxPerIP, ps.priority = limit, priority
+}
+
 // setSignerKey sets the signer key for signed announcements. Should be called before
 // starting the protocol handler.
 func (ps *clientPeerSet) setSignerKey(privateKey *ecdsa.PrivateKey) {
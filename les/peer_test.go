@@ -20,6 +20,7 @@ import (
 	"crypto/rand"
 	"errors"
 	"math/big"
+	"net"
 	"reflect"
 	"sort"
 	"testing"
@@ -165,3 +166,52 @@ func TestHandshake(t *testing.T) {
 		}
 	}
 }
+
+func TestClientPeerSetMaxPerIP(t *testing.T) {
+	var priorityID enode.ID
+	rand.Read(priorityID[:])
+
+	ps := newClientPeerSet()
+	ps.setMaxPeersPerIP(2, func(id enode.ID) bool { return id == priorityID })
+
+	newPeer := func(id enode.ID, ip string, trusted bool) *clientPeer {
+		if id == (enode.ID{}) {
+			rand.Read(id[:])
+		}
+		peer := newClientPeer(lpv4, NetworkId, p2p.NewPeer(id, "name", nil), nil)
+		peer.remoteHook = func() (net.IP, bool) { return net.ParseIP(ip), trusted }
+		return peer
+	}
+	var (
+		peer1 = newPeer(enode.ID{}, "10.0.0.1", false)
+		peer2 = newPeer(enode.ID{}, "10.0.0.1", false)
+		peer3 = newPeer(enode.ID{}, "10.0.0.1", false)
+	)
+	if err := ps.register(peer1); err != nil {
+		t.Fatalf("failed to register first peer: %v", err)
+	}
+	if err := ps.register(peer2); err != nil {
+		t.Fatalf("failed to register second peer: %v", err)
+	}
+	// The limit is reached for the IP, but not for others
+	if err := ps.register(peer3); err != errTooManyPeersPerIP {
+		t.Fatalf("peer over the limit error mismatch: have %v, want %v", err, errTooManyPeersPerIP)
+	}
+	if err := ps.register(newPeer(enode.ID{}, "10.0.0.2", false)); err != nil {
+		t.Fatalf("failed to register peer from another IP: %v", err)
+	}
+	// Trusted and priority peers are exempt and don't occupy slots either
+	if err := ps.register(newPeer(enode.ID{}, "10.0.0.1", true)); err != nil {
+		t.Fatalf("failed to register trusted peer: %v", err)
+	}
+	if err := ps.register(newPeer(priorityID, "10.0.0.1", false)); err != nil {
+		t.Fatalf("failed to register priority peer: %v", err)
+	}
+	// Unregistering a peer frees up its slot
+	if err := ps.unregister(peer1.ID()); err != nil {
+		t.Fatalf("failed to unregister peer: %v", err)
+	}
+	if err := ps.register(peer3); err != nil {
+		t.Fatalf("failed to register peer after a slot was freed: %v", err)
+	}
+}
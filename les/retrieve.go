@@ -26,8 +26,8 @@ import (
 )
 
 var (
-	retryQueue         = time.Millisecond * 100
-	hardRequestTimeout = time.Second * 10
+	retryQueue                = time.Millisecond * 100
+	defaultHardRequestTimeout = time.Second * 10
 )
 
 // retrieveManager is a layer on top of requestDistributor which takes care of
@@ -36,6 +36,7 @@ type retrieveManager struct {
 	dist               *requestDistributor
 	peers              *serverPeerSet
 	softRequestTimeout func() time.Duration
+	hardRequestTimeout time.Duration
 
 	lock     sync.RWMutex
 	sentReqs map[uint64]*sentReq
@@ -89,13 +90,18 @@ const (
 	rpNotDelivered
 )
 
-// newRetrieveManager creates the retrieve manager
-func newRetrieveManager(peers *serverPeerSet, dist *requestDistributor, srto func() time.Duration) *retrieveManager {
+// newRetrieveManager creates the retrieve manager. A zero hard timeout selects
+// the default one.
+func newRetrieveManager(peers *serverPeerSet, dist *requestDistributor, srto func() time.Duration, hrto time.Duration) *retrieveManager {
+	if hrto == 0 {
+		hrto = defaultHardRequestTimeout
+	}
 	return &retrieveManager{
 		peers:              peers,
 		dist:               dist,
 		sentReqs:           make(map[uint64]*sentReq),
 		softRequestTimeout: srto,
+		hardRequestTimeout: hrto,
 	}
 }
 
@@ -354,6 +360,7 @@ func (r *sentReq) tryRequest() {
 		r.eventsCh <- reqPeerEvent{event, p}
 		return
 	case <-time.After(r.rm.softRequestTimeout()):
+		requestSoftTimeoutMeter.Mark(1)
 		r.eventsCh <- reqPeerEvent{rpSoftTimeout, p}
 	}
 
@@ -365,8 +372,9 @@ func (r *sentReq) tryRequest() {
 			r.lock.Unlock()
 		}
 		r.eventsCh <- reqPeerEvent{event, p}
-	case <-time.After(hardRequestTimeout):
+	case <-time.After(r.rm.hardRequestTimeout):
 		hrto = true
+		requestHardTimeoutMeter.Mark(1)
 		r.eventsCh <- reqPeerEvent{rpHardTimeout, p}
 	}
 }
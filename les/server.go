@@ -18,6 +18,8 @@ package les
 
 import (
 	"crypto/ecdsa"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/mclock"
@@ -41,7 +43,13 @@ var (
 	defaultNegFactors = vfs.PriceFactors{TimeFactor: 0, CapacityFactor: 1, RequestFactor: 1}
 )
 
-const defaultConnectedBias = time.Minute * 3
+const (
+	defaultConnectedBias = time.Minute * 3
+
+	// priorityClientBalance is the positive balance granted to whitelisted
+	// priority clients, which are not charged for their connection.
+	priorityClientBalance = 1000000000000
+)
 
 type ethBackend interface {
 	ArchiveMode() bool
@@ -69,6 +77,10 @@ type LesServer struct {
 	servingQueue *servingQueue
 	clientPool   *vfs.ClientPool
 
+	priorityClients map[enode.ID]*enode.Node // Clients always served with priority
+	priorityGrants  map[enode.ID]uint64      // Balance granted to the connected priority clients
+	priorityLock    sync.Mutex               // Protects priorityGrants
+
 	minCapacity, maxCapacity uint64
 	threadsIdle              int // Request serving threads count when system is idle.
 	threadsBusy              int // Request serving threads count when system is busy(block insertion).
@@ -77,6 +89,10 @@ type LesServer struct {
 }
 
 func NewLesServer(node *node.Node, e ethBackend, config *ethconfig.Config) (*LesServer, error) {
+	// The bloom trie served to light clients is built from the default sections
+	if config.BloomBitsBlocks != 0 && config.BloomBitsBlocks != params.BloomBitsBlocks {
+		return nil, fmt.Errorf("light server requires the default bloom bits section size %d", params.BloomBitsBlocks)
+	}
 	lesDb, err := node.OpenDatabase("les.server", 0, 0, "eth/db/lesserver/", false)
 	if err != nil {
 		return nil, err
@@ -110,6 +126,17 @@ func NewLesServer(node *node.Node, e ethBackend, config *ethconfig.Config) (*Les
 		threadsIdle:  threads,
 		p2pSrv:       node.Server(),
 	}
+	srv.peers.setMaxPeersPerIP(config.LightPeersPerIP, srv.isPriorityClient)
+	srv.priorityClients = make(map[enode.ID]*enode.Node)
+	srv.priorityGrants = make(map[enode.ID]uint64)
+	for _, node := range config.LightPriorityClients {
+		n, err := parsePriorityClient(node)
+		if err != nil {
+			log.Warn("Failed to parse priority light client", "node", node, "err", err)
+			continue
+		}
+		srv.priorityClients[n.ID()] = n
+	}
 	issync := e.Synced
 	if config.LightNoSyncServe {
 		issync = func() bool { return true }
@@ -155,6 +182,76 @@ func NewLesServer(node *node.Node, e ethBackend, config *ethconfig.Config) (*Les
 	return srv, nil
 }
 
+// parsePriorityClient parses a whitelisted client given either as an enode URL
+// or just its node ID. The identity is all the p2p server needs to treat the
+// client as a trusted peer.
+func parsePriorityClient(node string) (*enode.Node, error) {
+	if id, err := enode.ParseID(node); err == nil {
+		return enode.SignNull(new(enr.Record), id), nil
+	}
+	return enode.Parse(enode.ValidSchemes, node)
+}
+
+// isPriorityClient reports whether the given client is whitelisted to always
+// be served with priority.
+func (s *LesServer) isPriorityClient(id enode.ID) bool {
+	_, ok := s.priorityClients[id]
+	return ok
+}
+
+// grantPriority tops up the balance of a whitelisted client so that it's
+// accepted with priority even if all free client slots are taken. The grant
+// is only valid while the client is connected, revokePriority takes it back.
+func (s *LesServer) grantPriority(id enode.ID) {
+	s.clientPool.BalanceOperation(id, "", func(balance vfs.AtomicBalanceOperator) {
+		pos, _ := balance.GetBalance()
+		if pos >= priorityClientBalance {
+			return
+		}
+		if _, _, err := balance.AddBalance(int64(priorityClientBalance - pos)); err != nil {
+			log.Warn("Failed to grant priority to light client", "id", id, "err", err)
+			return
+		}
+		s.priorityLock.Lock()
+		s.priorityGrants[id] = priorityClientBalance - pos
+		s.priorityLock.Unlock()
+	})
+}
+
+// revokePriority takes back the balance granted to a whitelisted client, so
+// that only the balance it had on its own is kept once it's disconnected.
+func (s *LesServer) revokePriority(id enode.ID) {
+	s.priorityLock.Lock()
+	granted, ok := s.priorityGrants[id]
+	delete(s.priorityGrants, id)
+	s.priorityLock.Unlock()
+
+	if !ok {
+		return
+	}
+	s.clientPool.BalanceOperation(id, "", func(balance vfs.AtomicBalanceOperator) {
+		if _, _, err := balance.AddBalance(-int64(granted)); err != nil {
+			log.Warn("Failed to revoke priority of light client", "id", id, "err", err)
+		}
+	})
+}
+
+// revokeAllPriority takes back the balance granted to all the connected
+// whitelisted clients. It's called on shutdown, before the client pool is
+// stopped and the balances can't be updated any more.
+func (s *LesServer) revokeAllPriority() {
+	s.priorityLock.Lock()
+	ids := make([]enode.ID, 0, len(s.priorityGrants))
+	for id := range s.priorityGrants {
+		ids = append(ids, id)
+	}
+	s.priorityLock.Unlock()
+
+	for _, id := range ids {
+		s.revokePriority(id)
+	}
+}
+
 func (s *LesServer) APIs() []rpc.API {
 	return []rpc.API{
 		{
@@ -204,6 +301,11 @@ func (s *LesServer) Start() error {
 	if s.p2pSrv.DiscV5 != nil {
 		s.p2pSrv.DiscV5.RegisterTalkHandler("vfx", s.vfluxServer.ServeEncoded)
 	}
+	// Exempt the priority clients from the peer limit of the p2p server, they
+	// would be dropped before reaching les otherwise if the server is full.
+	for _, node := range s.priorityClients {
+		s.p2pSrv.AddTrustedPeer(node)
+	}
 	return nil
 }
 
@@ -211,6 +313,10 @@ func (s *LesServer) Start() error {
 func (s *LesServer) Stop() error {
 	close(s.closeCh)
 
+	for _, node := range s.priorityClients {
+		s.p2pSrv.RemoveTrustedPeer(node)
+	}
+	s.revokeAllPriority()
 	s.clientPool.Stop()
 	if s.serverset != nil {
 		s.serverset.close()
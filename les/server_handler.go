@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/les/flowcontrol"
+	vfs "github.com/ethereum/go-ethereum/les/vflux/server"
 	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -147,17 +148,31 @@ func (h *serverHandler) handle(p *clientPeer) error {
 	if err := h.server.peers.register(p); err != nil {
 		return err
 	}
+	priority := h.server.isPriorityClient(p.ID())
+	if priority {
+		h.server.grantPriority(p.ID())
+	}
 	if p.balance = h.server.clientPool.Register(p); p.balance == nil {
+		if priority {
+			h.server.revokePriority(p.ID())
+		}
 		h.server.peers.unregister(p.ID())
 		p.Log().Debug("Client pool already closed")
 		return p2p.DiscRequested
 	}
+	if priority {
+		// Whitelisted clients are not charged, keep their balance intact
+		p.balance.SetPriceFactors(vfs.PriceFactors{}, vfs.PriceFactors{})
+	}
 	p.connectedAt = mclock.Now()
 
 	var wg sync.WaitGroup // Wait group used to track all in-flight task routines.
 	defer func() {
 		wg.Wait() // Ensure all background task routines have exited.
 		h.server.clientPool.Unregister(p)
+		if priority {
+			h.server.revokePriority(p.ID())
+		}
 		h.server.peers.unregister(p.ID())
 		p.balance = nil
 		connectionTimer.Update(time.Duration(mclock.Now() - p.connectedAt))
@@ -251,6 +266,7 @@ func (h *serverHandler) afterHandle(p *clientPeer, reqID, responseCount uint64,
 	}
 	bv := p.fcClient.RequestProcessed(reqID, responseCount, maxCost, realCost)
 	if reply != nil {
+		atomic.AddUint64(&p.servedCount, 1)
 		// Feed cost tracker request serving statistic.
 		h.server.costTracker.updateStats(msg.Code, reqCnt, task.servingTime, realCost)
 		// Reduce priority "balance" for the specific peer.
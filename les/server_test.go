@@ -0,0 +1,146 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	vfs "github.com/ethereum/go-ethereum/les/vflux/server"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Tests that whitelisted priority clients are granted a positive balance only
+// while connected, leaving their own balance intact afterwards.
+func TestPriorityClientGrant(t *testing.T) {
+	var (
+		clock = &mclock.Simulated{}
+		db    = rawdb.NewMemoryDatabase()
+		id1   = enode.ID{0x01}
+		id2   = enode.ID{0x02}
+	)
+	const own = 10000000 // Above the persisted balance threshold
+
+	newServer := func() *LesServer {
+		server := &LesServer{
+			priorityClients: map[enode.ID]*enode.Node{id1: nil, id2: nil},
+			priorityGrants:  make(map[enode.ID]uint64),
+			clientPool:      vfs.NewClientPool(db, 1, defaultConnectedBias, clock, alwaysTrueFn),
+		}
+		server.clientPool.Start()
+		return server
+	}
+	balance := func(server *LesServer, id enode.ID) (pos uint64) {
+		server.clientPool.BalanceOperation(id, "", func(balance vfs.AtomicBalanceOperator) {
+			pos, _ = balance.GetBalance()
+		})
+		return pos
+	}
+	server := newServer()
+	if !server.isPriorityClient(id1) || server.isPriorityClient(enode.ID{0x03}) {
+		t.Fatalf("priority client whitelist mismatch")
+	}
+	// Top up the balance of a client which has some balance on its own
+	server.clientPool.BalanceOperation(id1, "", func(balance vfs.AtomicBalanceOperator) {
+		balance.AddBalance(own)
+	})
+	server.grantPriority(id1)
+	if pos := balance(server, id1); pos != priorityClientBalance {
+		t.Fatalf("granted balance mismatch: have %d, want %d", pos, uint64(priorityClientBalance))
+	}
+	// Revoking the grant restores the original balance
+	server.revokePriority(id1)
+	if pos := balance(server, id1); pos != own {
+		t.Fatalf("balance after revoke mismatch: have %d, want %d", pos, own)
+	}
+	// Grants still held on shutdown are revoked too and don't get persisted
+	server.grantPriority(id2)
+	server.revokeAllPriority()
+	server.clientPool.Stop()
+
+	server = newServer()
+	defer server.clientPool.Stop()
+	if pos := balance(server, id1); pos != own {
+		t.Fatalf("persisted balance mismatch: have %d, want %d", pos, own)
+	}
+	if pos := balance(server, id2); pos != 0 {
+		t.Fatalf("persisted balance mismatch: have %d, want %d", pos, 0)
+	}
+}
+
+// Tests that priority clients can be whitelisted by enode URL or node ID, both
+// resulting in a node with the right identity to be trusted by the p2p server.
+func TestParsePriorityClient(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	want := enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303)
+
+	for _, node := range []string{want.URLv4(), want.ID().String()} {
+		have, err := parsePriorityClient(node)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", node, err)
+		}
+		if have.ID() != want.ID() {
+			t.Errorf("%q: node ID mismatch: have %v, want %v", node, have.ID(), want.ID())
+		}
+	}
+	if _, err := parsePriorityClient("invalid"); err == nil {
+		t.Errorf("invalid node accepted")
+	}
+}
+
+// Tests that the client info reported over the API reflects the number of
+// requests served to a connected client and the duration of its connection.
+func TestClientInfoServedRequests(t *testing.T) {
+	netconfig := testnetConfig{
+		blocks:    10,
+		protocol:  lpv4,
+		nopruning: true,
+	}
+	server, _, tearDown := newClientServerEnv(t, netconfig)
+	defer tearDown()
+
+	start := mclock.Now()
+	rawPeer, closePeer, _ := server.newRawPeer(t, "peer", lpv4)
+	defer closePeer()
+
+	const requests = 3
+	bc := server.handler.blockchain
+	for i := uint64(1); i <= requests; i++ {
+		sendRequest(rawPeer.app, GetBlockHeadersMsg, i, &GetBlockHeadersData{Origin: hashOrNumber{Number: i}, Amount: 1})
+		if err := expectResponse(rawPeer.app, BlockHeadersMsg, i, testBufLimit, []*types.Header{bc.GetHeaderByNumber(i)}); err != nil {
+			t.Fatalf("request %d: headers mismatch: %v", i, err)
+		}
+	}
+	api := NewPrivateLightServerAPI(server.handler.server)
+	id := rawPeer.cpeer.ID()
+	info := api.ClientInfo([]string{id.String()})[id]
+	if info == nil {
+		t.Fatalf("no info for connected client")
+	}
+	if served := info["servedRequests"].(uint64); served != requests {
+		t.Errorf("served requests mismatch: have %d, want %d", served, requests)
+	}
+	elapsed := float64(mclock.Now()-start) / float64(time.Second)
+	if conn := info["connectionTime"].(float64); conn <= 0 || conn > elapsed {
+		t.Errorf("connection time out of range: have %f, want (0, %f]", conn, elapsed)
+	}
+}
@@ -28,9 +28,40 @@ import (
 	"github.com/ethereum/go-ethereum/light"
 )
 
+// errStateHistoryUnavailable is returned if a state is requested which is older
+// than the configured light state history.
+var errStateHistoryUnavailable = errors.New("state history not available")
+
+// stateAtHeader returns the ODR backed state database of the given header, or
+// an error if it's beyond the configured light state history.
+func (leth *LightEthereum) stateAtHeader(ctx context.Context, header *types.Header) (*state.StateDB, error) {
+	if limit := leth.config.LightStateHistory; limit != 0 {
+		head, number := leth.blockchain.CurrentHeader().Number.Uint64(), header.Number.Uint64()
+		if number+limit < head {
+			return nil, fmt.Errorf("%w: block #%d, oldest available #%d", errStateHistoryUnavailable, number, head-limit)
+		}
+	}
+	return light.NewState(ctx, header, leth.odr), nil
+}
+
+// prefetchState retrieves the accounts and storage slots the given messages are
+// known to touch in the state of the given header in batches, instead of having
+// the execution resolve them one by one.
+func (leth *LightEthereum) prefetchState(ctx context.Context, header *types.Header, msgs ...core.Message) error {
+	list := types.AccessList{{Address: header.Coinbase}}
+	for _, msg := range msgs {
+		list = append(list, types.AccessTuple{Address: msg.From()})
+		if to := msg.To(); to != nil {
+			list = append(list, types.AccessTuple{Address: *to})
+		}
+		list = append(list, msg.AccessList()...)
+	}
+	return light.PrefetchState(ctx, leth.odr, header, list)
+}
+
 // stateAtBlock retrieves the state database associated with a certain block.
 func (leth *LightEthereum) stateAtBlock(ctx context.Context, block *types.Block, reexec uint64) (*state.StateDB, error) {
-	return light.NewState(ctx, block.Header(), leth.odr), nil
+	return leth.stateAtHeader(ctx, block.Header())
 }
 
 // stateAtTransaction returns the execution environment of a certain transaction.
@@ -51,11 +82,24 @@ func (leth *LightEthereum) stateAtTransaction(ctx context.Context, block *types.
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, statedb, nil
 	}
-	// Recompute transactions up to the target index.
+	// Assemble the transaction call messages up to the target index and retrieve
+	// the state they touch in a few batched requests.
 	signer := types.MakeSigner(leth.blockchain.Config(), block.Number())
+	var msgs []core.Message
 	for idx, tx := range block.Transactions() {
-		// Assemble the transaction call message and return if the requested offset
+		if idx > txIndex {
+			break
+		}
 		msg, _ := tx.AsMessage(signer, block.BaseFee())
+		msgs = append(msgs, msg)
+	}
+	if err := leth.prefetchState(ctx, parent.Header(), msgs...); err != nil {
+		return nil, vm.BlockContext{}, nil, err
+	}
+	// Recompute transactions up to the target index.
+	for idx, tx := range block.Transactions() {
+		// Return the transaction call message if the requested offset
+		msg := msgs[idx]
 		txContext := core.NewEVMTxContext(msg)
 		context := core.NewEVMBlockContext(block.Header(), leth.blockchain, nil)
 		statedb.Prepare(tx.Hash(), idx)
@@ -0,0 +1,56 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Tests that the light state history limit allows access to exactly the given
+// number of recent blocks and rejects anything older.
+func TestStateHistoryLimit(t *testing.T) {
+	netconfig := testnetConfig{
+		blocks:    4,
+		protocol:  lpv4,
+		connect:   true,
+		nopruning: true,
+	}
+	_, client, tearDown := newClientServerEnv(t, netconfig)
+	defer tearDown()
+
+	leth := client.handler.backend
+	if head := leth.blockchain.CurrentHeader().Number.Uint64(); head != 4 {
+		t.Fatalf("client head mismatch: have %d, want 4", head)
+	}
+	leth.config.LightStateHistory = 2
+
+	// The oldest block within the limit (number+limit == head) is available
+	if _, err := leth.stateAtHeader(context.Background(), leth.blockchain.GetHeaderByNumber(2)); err != nil {
+		t.Fatalf("failed to access state within the history limit: %v", err)
+	}
+	// One block older is rejected
+	if _, err := leth.stateAtHeader(context.Background(), leth.blockchain.GetHeaderByNumber(1)); !errors.Is(err, errStateHistoryUnavailable) {
+		t.Fatalf("state access beyond the history limit error mismatch: have %v, want %v", err, errStateHistoryUnavailable)
+	}
+	// No limit makes all the history accessible
+	leth.config.LightStateHistory = 0
+	if _, err := leth.stateAtHeader(context.Background(), leth.blockchain.GetHeaderByNumber(0)); err != nil {
+		t.Fatalf("failed to access state without history limit: %v", err)
+	}
+}
@@ -595,7 +595,7 @@ func newClientServerEnv(t *testing.T, config testnetConfig) (*testServer, *testC
 		clock = &mclock.Simulated{}
 	}
 	dist := newRequestDistributor(speers, clock)
-	rm := newRetrieveManager(speers, dist, func() time.Duration { return time.Millisecond * 500 })
+	rm := newRetrieveManager(speers, dist, func() time.Duration { return time.Millisecond * 500 }, 0)
 	odr := NewLesOdr(cdb, light.TestClientIndexerConfig, speers, rm)
 
 	sindexers := testIndexers(sdb, nil, light.TestServerIndexerConfig, true)
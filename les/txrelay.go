@@ -20,36 +20,95 @@ import (
 	"context"
 	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+const (
+	txRebroadcastInterval = time.Minute // Time interval to rebroadcast the not yet included transactions
+	txRebroadcastTimeout  = time.Hour   // Maximum time a transaction is rebroadcast before giving up
+	txRebroadcastPeers    = 3           // Number of servers to rebroadcast a pending transaction to
+)
+
 type lesTxRelay struct {
 	txSent       map[common.Hash]*types.Transaction
-	txPending    map[common.Hash]struct{}
+	txPending    map[common.Hash]mclock.AbsTime // Pending transactions and the time they were (re)submitted
 	peerList     []*serverPeer
 	peerStartPos int
 	lock         sync.Mutex
 	stop         chan struct{}
+	wg           sync.WaitGroup
+	clock        mclock.Clock
 
 	retriever *retrieveManager
 }
 
-func newLesTxRelay(ps *serverPeerSet, retriever *retrieveManager) *lesTxRelay {
+func newLesTxRelay(ps *serverPeerSet, retriever *retrieveManager, clock mclock.Clock) *lesTxRelay {
 	r := &lesTxRelay{
 		txSent:    make(map[common.Hash]*types.Transaction),
-		txPending: make(map[common.Hash]struct{}),
+		txPending: make(map[common.Hash]mclock.AbsTime),
 		retriever: retriever,
 		stop:      make(chan struct{}),
+		clock:     clock,
 	}
 	ps.subscribe(r)
+	r.wg.Add(1)
+	go r.loop()
 	return r
 }
 
 func (ltrx *lesTxRelay) Stop() {
 	close(ltrx.stop)
+	ltrx.wg.Wait()
+}
+
+// loop periodically rebroadcasts the transactions which are still not included
+// in the chain to multiple servers. It protects against a server silently
+// dropping a relayed transaction.
+func (ltrx *lesTxRelay) loop() {
+	defer ltrx.wg.Done()
+
+	timer := ltrx.clock.NewTimer(txRebroadcastInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C():
+			ltrx.rebroadcast()
+			timer.Reset(txRebroadcastInterval)
+		case <-ltrx.stop:
+			return
+		}
+	}
+}
+
+// rebroadcast resends all pending transactions which haven't timed out yet and
+// stops tracking the expired ones.
+func (ltrx *lesTxRelay) rebroadcast() {
+	ltrx.lock.Lock()
+	defer ltrx.lock.Unlock()
+
+	var (
+		now = ltrx.clock.Now()
+		txs types.Transactions
+	)
+	for hash, added := range ltrx.txPending {
+		if now.Sub(added) > txRebroadcastTimeout {
+			log.Debug("Stop rebroadcasting transaction", "hash", hash, "age", common.PrettyDuration(now.Sub(added)))
+			delete(ltrx.txPending, hash)
+			delete(ltrx.txSent, hash) // allow tracking it again if resubmitted
+			continue
+		}
+		txs = append(txs, ltrx.txSent[hash])
+	}
+	if len(txs) > 0 {
+		ltrx.send(txs, txRebroadcastPeers)
+	}
 }
 
 func (ltrx *lesTxRelay) registerPeer(p *serverPeer) {
@@ -90,7 +149,7 @@ func (ltrx *lesTxRelay) send(txs types.Transactions, count int) {
 		_, ok := ltrx.txSent[hash]
 		if !ok {
 			ltrx.txSent[hash] = tx
-			ltrx.txPending[hash] = struct{}{}
+			ltrx.txPending[hash] = ltrx.clock.Now()
 		}
 		if len(ltrx.peerList) > 0 {
 			cnt := count
@@ -142,7 +201,7 @@ func (ltrx *lesTxRelay) Send(txs types.Transactions) {
 	ltrx.lock.Lock()
 	defer ltrx.lock.Unlock()
 
-	ltrx.send(txs, 3)
+	ltrx.send(txs, txRebroadcastPeers)
 }
 
 func (ltrx *lesTxRelay) NewHead(head common.Hash, mined []common.Hash, rollback []common.Hash) {
@@ -154,7 +213,9 @@ func (ltrx *lesTxRelay) NewHead(head common.Hash, mined []common.Hash, rollback
 	}
 
 	for _, hash := range rollback {
-		ltrx.txPending[hash] = struct{}{}
+		if _, ok := ltrx.txSent[hash]; ok {
+			ltrx.txPending[hash] = ltrx.clock.Now()
+		}
 	}
 
 	if len(ltrx.txPending) > 0 {
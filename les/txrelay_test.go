@@ -0,0 +1,71 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that the relay keeps rebroadcasting pending transactions until they time
+// out, and that expired transactions are tracked again when resubmitted.
+func TestTxRelayRebroadcastExpiry(t *testing.T) {
+	clock := &mclock.Simulated{}
+	relay := newLesTxRelay(newServerPeerSet(), nil, clock)
+	defer relay.Stop()
+
+	tracked := func(hash common.Hash) (sent bool, pending bool) {
+		relay.lock.Lock()
+		defer relay.lock.Unlock()
+
+		_, sent = relay.txSent[hash]
+		_, pending = relay.txPending[hash]
+		return sent, pending
+	}
+	// advance moves the clock a rebroadcast interval at a time, waiting for each
+	// rebroadcast round to finish and reschedule before moving on.
+	advance := func(d time.Duration) {
+		for elapsed := time.Duration(0); elapsed < d; elapsed += txRebroadcastInterval {
+			clock.WaitForTimers(1)
+			clock.Run(txRebroadcastInterval)
+		}
+		clock.WaitForTimers(1)
+	}
+	tx := types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	relay.Send(types.Transactions{tx})
+
+	// The transaction must survive all rebroadcast rounds until its timeout
+	advance(txRebroadcastTimeout)
+	if sent, pending := tracked(tx.Hash()); !sent || !pending {
+		t.Fatalf("transaction dropped before timeout: sent %v, pending %v", sent, pending)
+	}
+	// Once timed out, it must be forgotten altogether
+	advance(txRebroadcastInterval)
+	if sent, pending := tracked(tx.Hash()); sent || pending {
+		t.Fatalf("expired transaction still tracked: sent %v, pending %v", sent, pending)
+	}
+	// Resubmitting it must start rebroadcasting again
+	relay.Send(types.Transactions{tx})
+	if sent, pending := tracked(tx.Hash()); !sent || !pending {
+		t.Fatalf("resubmitted transaction not tracked: sent %v, pending %v", sent, pending)
+	}
+}
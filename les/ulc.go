@@ -18,6 +18,7 @@ package les
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
@@ -42,6 +43,9 @@ func newULC(servers []string, fraction int) (*ulc, error) {
 	if len(keys) == 0 {
 		return nil, errors.New("no trusted servers")
 	}
+	if fraction <= 0 || fraction > 100 {
+		return nil, fmt.Errorf("invalid trusted fraction %d, must be in (0, 100]", fraction)
+	}
 	return &ulc{
 		keys:     keys,
 		fraction: fraction,
@@ -28,6 +28,22 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
+func TestULCFractionValidation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	id := enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 35000, 35000).URLv4()
+
+	for _, fraction := range []int{-1, 0, 101} {
+		if _, err := newULC([]string{id}, fraction); err == nil {
+			t.Errorf("fraction %d: expected error", fraction)
+		}
+	}
+	for _, fraction := range []int{1, 75, 100} {
+		if _, err := newULC([]string{id}, fraction); err != nil {
+			t.Errorf("fraction %d: unexpected error: %v", fraction, err)
+		}
+	}
+}
+
 func TestULCAnnounceThresholdLes2(t *testing.T) { testULCAnnounceThreshold(t, 2) }
 func TestULCAnnounceThresholdLes3(t *testing.T) { testULCAnnounceThreshold(t, 3) }
 
@@ -41,8 +41,9 @@ import (
 )
 
 var (
-	bodyCacheLimit  = 256
-	blockCacheLimit = 256
+	bodyCacheLimit     = 256
+	blockCacheLimit    = 256
+	receiptsCacheLimit = 32
 )
 
 // LightChain represents a canonical chain that by default only handles block
@@ -60,9 +61,10 @@ type LightChain struct {
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
-	bodyCache    *lru.Cache // Cache for the most recent block bodies
-	bodyRLPCache *lru.Cache // Cache for the most recent block bodies in RLP encoded format
-	blockCache   *lru.Cache // Cache for the most recent entire blocks
+	bodyCache     *lru.Cache // Cache for the most recent block bodies
+	bodyRLPCache  *lru.Cache // Cache for the most recent block bodies in RLP encoded format
+	receiptsCache *lru.Cache // Cache for the most recent receipts per block
+	blockCache    *lru.Cache // Cache for the most recent entire blocks
 
 	chainmu sync.RWMutex // protects header inserts
 	quit    chan struct{}
@@ -80,6 +82,7 @@ type LightChain struct {
 func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.Engine, checkpoint *params.TrustedCheckpoint) (*LightChain, error) {
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
+	receiptsCache, _ := lru.New(receiptsCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
 
 	bc := &LightChain{
@@ -89,6 +92,7 @@ func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.
 		quit:          make(chan struct{}),
 		bodyCache:     bodyCache,
 		bodyRLPCache:  bodyRLPCache,
+		receiptsCache: receiptsCache,
 		blockCache:    blockCache,
 		engine:        engine,
 	}
@@ -267,6 +271,26 @@ func (lc *LightChain) GetBodyRLP(ctx context.Context, hash common.Hash) (rlp.Raw
 	return body, nil
 }
 
+// GetReceiptsByHash retrieves the receipts of a block from the database or ODR
+// service by hash, caching them if found.
+func (lc *LightChain) GetReceiptsByHash(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	// Short circuit if the receipts are already in the cache, retrieve otherwise
+	if cached, ok := lc.receiptsCache.Get(hash); ok {
+		return cached.(types.Receipts), nil
+	}
+	number := lc.hc.GetBlockNumber(hash)
+	if number == nil {
+		return nil, errors.New("unknown block")
+	}
+	receipts, err := GetBlockReceipts(ctx, lc.odr, hash, *number)
+	if err != nil {
+		return nil, err
+	}
+	// Cache the found receipts for next time and return
+	lc.receiptsCache.Add(hash, receipts)
+	return receipts, nil
+}
+
 // HasBlock checks if a block is fully present in the database or not, caching
 // it if present.
 func (lc *LightChain) HasBlock(hash common.Hash, number uint64) bool {
@@ -35,6 +35,10 @@ var NoOdr = context.Background()
 // ErrNoPeers is returned if no peers capable of serving a queued request are available
 var ErrNoPeers = errors.New("no suitable peers available")
 
+// ErrTxStatusMismatch is reported in the status of a transaction by a quorum
+// transaction status retrieval if servers include it at different positions.
+var ErrTxStatusMismatch = errors.New("conflicting transaction status from servers")
+
 // OdrBackend is an interface to a backend service that handles ODR retrievals type
 type OdrBackend interface {
 	Database() ethdb.Database
@@ -93,6 +97,18 @@ func (req *TrieRequest) StoreResult(db ethdb.Database) {
 	req.Proof.Store(db)
 }
 
+// TrieBatchRequest is the ODR request type for retrieving multiple state/storage
+// trie entries of the same block in a single round trip
+type TrieBatchRequest struct {
+	Reqs  []*TrieRequest
+	Proof *NodeSet
+}
+
+// StoreResult stores the retrieved data in local database
+func (req *TrieBatchRequest) StoreResult(db ethdb.Database) {
+	req.Proof.Store(db)
+}
+
 // CodeRequest is the ODR request type for retrieving contract code
 type CodeRequest struct {
 	Id   *TrieID // references storage trie of the account
@@ -183,10 +199,16 @@ type TxStatus struct {
 	Error  string
 }
 
-// TxStatusRequest is the ODR request type for retrieving transaction status
+// TxStatusRequest is the ODR request type for retrieving transaction status.
+//
+// Transaction status responses are not verifiable, so the request can require
+// a quorum of servers to report the same status before it's accepted. Zero
+// means the quorum configured for the client is used, one that the first
+// non-unknown response is trusted.
 type TxStatusRequest struct {
 	Hashes []common.Hash
 	Status []TxStatus
+	Quorum int
 }
 
 // StoreResult stores the retrieved data in local database
@@ -319,3 +319,59 @@ func testChainOdr(t *testing.T, protocol int, fn odrTestFn) {
 	odr.disable = true
 	test(len(gchain))
 }
+
+// Tests that receipts retrieved through the light chain are cached, serving any
+// further lookups without an ODR round trip.
+func TestLightChainReceiptsCache(t *testing.T) {
+	var (
+		sdb   = rawdb.NewMemoryDatabase()
+		ldb   = rawdb.NewMemoryDatabase()
+		gspec = core.Genesis{
+			Alloc:   core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		genesis = gspec.MustCommit(sdb)
+	)
+	gspec.MustCommit(ldb)
+	blockchain, _ := core.NewBlockChain(sdb, nil, params.TestChainConfig, ethash.NewFullFaker(), vm.Config{}, nil, nil)
+	gchain, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), sdb, 4, testChainGen)
+	if _, err := blockchain.InsertChain(gchain); err != nil {
+		t.Fatal(err)
+	}
+	odr := &testOdr{sdb: sdb, ldb: ldb, indexerConfig: TestClientIndexerConfig}
+	lightchain, err := NewLightChain(odr, params.TestChainConfig, ethash.NewFullFaker(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := make([]*types.Header, len(gchain))
+	for i, block := range gchain {
+		headers[i] = block.Header()
+	}
+	if _, err := lightchain.InsertHeaderChain(headers, 1); err != nil {
+		t.Fatal(err)
+	}
+	block := gchain[1]
+	want := blockchain.GetReceiptsByHash(block.Hash())
+
+	// The first lookup has to be retrieved from the network
+	have, err := lightchain.GetReceiptsByHash(context.Background(), block.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve receipts: %v", err)
+	}
+	if len(have) != len(want) {
+		t.Fatalf("receipt count mismatch: have %d, want %d", len(have), len(want))
+	}
+	// Drop the locally stored copy and disable ODR, the cache must serve it
+	rawdb.DeleteReceipts(ldb, block.Hash(), block.NumberU64())
+	odr.disable = true
+
+	cached, err := lightchain.GetReceiptsByHash(context.Background(), block.Hash())
+	if err != nil {
+		t.Fatalf("cached receipts not served: %v", err)
+	}
+	for i := range want {
+		if cached[i].TxHash != want[i].TxHash || cached[i].CumulativeGasUsed != want[i].CumulativeGasUsed {
+			t.Errorf("receipt %d mismatch: have %x/%d, want %x/%d", i, cached[i].TxHash, cached[i].CumulativeGasUsed, want[i].TxHash, want[i].CumulativeGasUsed)
+		}
+	}
+}
@@ -25,8 +25,12 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 // errNonCanonicalHash is returned if the requested chain data doesn't belong
@@ -34,6 +38,10 @@ import (
 // by the CHT or Bloom trie for verification.
 var errNonCanonicalHash = errors.New("hash is not currently canonical")
 
+// maxTrieBatch is the maximum number of trie entries retrieved by a single
+// batched proof request, matching the proof limit of the les servers.
+const maxTrieBatch = 64
+
 // GetHeaderByNumber retrieves the canonical block header corresponding to the
 // given number. The returned header is proven by local CHT.
 func GetHeaderByNumber(ctx context.Context, odr OdrBackend, number uint64) (*types.Header, error) {
@@ -292,3 +300,90 @@ func GetTransaction(ctx context.Context, odr OdrBackend, txHash common.Hash) (*t
 	}
 	return body.Transactions[pos.Index], pos.BlockHash, pos.BlockIndex, pos.Index, nil
 }
+
+// PrefetchState retrieves the accounts and storage slots of the given access
+// list in the state of the given header, so that the execution touching them
+// doesn't need to resolve them one by one. The accounts are retrieved with one
+// batched request (per maxTrieBatch entries) and their storage slots with
+// another one, entries available locally are skipped.
+func PrefetchState(ctx context.Context, odr OdrBackend, header *types.Header, list types.AccessList) error {
+	var (
+		db      = odr.Database()
+		id      = StateTrieID(header)
+		reqs    []*TrieRequest
+		account = make(map[common.Address]bool)
+	)
+	for _, tuple := range list {
+		if account[tuple.Address] {
+			continue
+		}
+		account[tuple.Address] = true
+		key := crypto.Keccak256(tuple.Address[:])
+		if !hasTrieEntry(db, id.Root, key) {
+			reqs = append(reqs, &TrieRequest{Id: id, Key: key})
+		}
+	}
+	if err := retrieveTrieBatch(ctx, odr, reqs); err != nil {
+		return err
+	}
+	// All accounts are available locally, resolve the storage tries
+	st, err := trie.NewSecure(id.Root, trie.NewDatabase(db))
+	if err != nil {
+		return err
+	}
+	reqs = nil
+	for _, tuple := range list {
+		if len(tuple.StorageKeys) == 0 {
+			continue
+		}
+		enc, err := st.TryGet(tuple.Address[:])
+		if err != nil {
+			return err
+		}
+		if len(enc) == 0 {
+			continue // non-existent account, no storage to retrieve
+		}
+		var data state.Account
+		if err := rlp.DecodeBytes(enc, &data); err != nil {
+			return err
+		}
+		if data.Root == types.EmptyRootHash {
+			continue
+		}
+		sid := StorageTrieID(id, crypto.Keccak256Hash(tuple.Address[:]), data.Root)
+		for _, slot := range tuple.StorageKeys {
+			key := crypto.Keccak256(slot[:])
+			if !hasTrieEntry(db, sid.Root, key) {
+				reqs = append(reqs, &TrieRequest{Id: sid, Key: key})
+			}
+		}
+	}
+	return retrieveTrieBatch(ctx, odr, reqs)
+}
+
+// hasTrieEntry reports whether the given (hashed) key can be resolved from the
+// local database in the trie with the given root.
+func hasTrieEntry(db ethdb.Database, root common.Hash, key []byte) bool {
+	t, err := trie.New(root, trie.NewDatabase(db))
+	if err != nil {
+		return false
+	}
+	_, err = t.TryGet(key)
+	return err == nil
+}
+
+// retrieveTrieBatch retrieves the proofs of the given trie entries, batching as
+// many of them in a single request as the servers accept.
+func retrieveTrieBatch(ctx context.Context, odr OdrBackend, reqs []*TrieRequest) error {
+	for len(reqs) > 0 {
+		batch := reqs
+		if len(batch) > maxTrieBatch {
+			batch = batch[:maxTrieBatch]
+		}
+		if err := odr.Retrieve(ctx, &TrieBatchRequest{Reqs: batch}); err != nil {
+			return err
+		}
+		reqs = reqs[len(batch):]
+	}
+	return nil
+}
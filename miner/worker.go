@@ -752,7 +752,11 @@ func (w *worker) updateSnapshot() {
 func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Address) ([]*types.Log, error) {
 	snap := w.current.state.Snapshot()
 
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, tx, &w.current.header.GasUsed, *w.chain.GetVMConfig())
+	// Mining attempts are not imports, keep them out of the opcode statistics
+	vmConfig := *w.chain.GetVMConfig()
+	vmConfig.OpcodeStats = nil
+
+	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, tx, &w.current.header.GasUsed, vmConfig)
 	if err != nil {
 		w.current.state.RevertToSnapshot(snap)
 		return nil, err
@@ -17,9 +17,11 @@
 package params
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/crypto/sha3"
@@ -247,16 +249,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -341,6 +343,56 @@ type ChainConfig struct {
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+
+	// Additional precompiled contracts for private networks
+	Precompiles map[common.Address]*CustomPrecompile `json:"precompiles,omitempty"`
+
+	// Gas cost overrides of EVM opcodes for private networks
+	OpcodeGas map[string]*OpcodeGas `json:"opcodeGas,omitempty"`
+}
+
+// CustomPrecompile declares an additional precompiled contract, backed by a Go
+// implementation registered in core/vm under the given name.
+type CustomPrecompile struct {
+	Name  string   `json:"name"`            // Name of the registered implementation
+	Block *big.Int `json:"block,omitempty"` // Activation block (nil = active from genesis)
+}
+
+// activation returns the block the precompile is activated at, or nil if it
+// is not declared at all.
+func (p *CustomPrecompile) activation() *big.Int {
+	if p == nil {
+		return nil
+	}
+	if p.Block == nil {
+		return common.Big0
+	}
+	return p.Block
+}
+
+// OpcodeGas overrides the constant gas cost of an EVM opcode, keeping any
+// dynamic costs of the opcode (memory expansion, state access) intact.
+//
+// If Dynamic is set, the dynamic costs are dropped too, making Gas the whole
+// cost of the opcode. This is needed to reprice opcodes charging mostly dynamic
+// gas, such as the EIP-2929 cold and warm state accesses of SLOAD. It is not
+// allowed for opcodes expanding memory.
+type OpcodeGas struct {
+	Gas     uint64   `json:"gas"`               // Gas charged for the opcode
+	Dynamic bool     `json:"dynamic,omitempty"` // Whether Gas replaces the dynamic costs too
+	Block   *big.Int `json:"block,omitempty"`   // Activation block (nil = active from genesis)
+}
+
+// activation returns the block the override is activated at, or nil if it is
+// not declared at all.
+func (o *OpcodeGas) activation() *big.Int {
+	if o == nil {
+		return nil
+	}
+	if o.Block == nil {
+		return common.Big0
+	}
+	return o.Block
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -392,6 +444,44 @@ func (c *ChainConfig) String() string {
 	)
 }
 
+// CustomPrecompile returns the name of the implementation of the custom
+// precompiled contract at the given address, if one is active at block num.
+func (c *ChainConfig) CustomPrecompile(addr common.Address, num *big.Int) (string, bool) {
+	p := c.Precompiles[addr]
+	if !isForked(p.activation(), num) {
+		return "", false
+	}
+	return p.Name, true
+}
+
+// customPrecompiles returns the addresses of the custom precompiled contracts
+// active at block num, sorted by address.
+func (c *ChainConfig) customPrecompiles(num *big.Int) []common.Address {
+	var addrs []common.Address
+	for addr, p := range c.Precompiles {
+		if isForked(p.activation(), num) {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
+
+// opcodeGas returns the gas cost overrides active at block num, keyed by opcode
+// name.
+func (c *ChainConfig) opcodeGas(num *big.Int) map[string]OpcodeGas {
+	var costs map[string]OpcodeGas
+	for name, o := range c.OpcodeGas {
+		if isForked(o.activation(), num) {
+			if costs == nil {
+				costs = make(map[string]OpcodeGas)
+			}
+			costs[name] = *o
+		}
+	}
+	return costs
+}
+
 // IsHomestead returns whether num is either equal to the homestead block or greater.
 func (c *ChainConfig) IsHomestead(num *big.Int) bool {
 	return isForked(c.HomesteadBlock, num)
@@ -568,6 +658,64 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.LondonBlock, newcfg.LondonBlock, head) {
 		return newCompatError("London fork block", c.LondonBlock, newcfg.LondonBlock)
 	}
+	if err := checkPrecompilesCompatible(c.Precompiles, newcfg.Precompiles, head); err != nil {
+		return err
+	}
+	if err := checkOpcodeGasCompatible(c.OpcodeGas, newcfg.OpcodeGas, head); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkPrecompilesCompatible checks whether the custom precompiled contracts
+// already active at head are neither rescheduled nor swapped out.
+func checkPrecompilesCompatible(stored, next map[common.Address]*CustomPrecompile, head *big.Int) *ConfigCompatError {
+	addrs := make([]common.Address, 0, len(stored)+len(next))
+	for addr := range stored {
+		addrs = append(addrs, addr)
+	}
+	for addr := range next {
+		if _, ok := stored[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+	for _, addr := range addrs {
+		s, n := stored[addr], next[addr]
+		if isForkIncompatible(s.activation(), n.activation(), head) {
+			return newCompatError(fmt.Sprintf("precompile %x activation block", addr), s.activation(), n.activation())
+		}
+		if isForked(s.activation(), head) && s.Name != n.Name {
+			return newCompatError(fmt.Sprintf("precompile %x implementation", addr), s.activation(), n.activation())
+		}
+	}
+	return nil
+}
+
+// checkOpcodeGasCompatible checks whether the opcode gas overrides already
+// active at head are neither rescheduled nor repriced.
+func checkOpcodeGasCompatible(stored, next map[string]*OpcodeGas, head *big.Int) *ConfigCompatError {
+	names := make([]string, 0, len(stored)+len(next))
+	for name := range stored {
+		names = append(names, name)
+	}
+	for name := range next {
+		if _, ok := stored[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s, n := stored[name], next[name]
+		if isForkIncompatible(s.activation(), n.activation(), head) {
+			return newCompatError(fmt.Sprintf("%s gas override block", name), s.activation(), n.activation())
+		}
+		if isForked(s.activation(), head) && (s.Gas != n.Gas || s.Dynamic != n.Dynamic) {
+			return newCompatError(fmt.Sprintf("%s gas override cost", name), s.activation(), n.activation())
+		}
+	}
 	return nil
 }
 
@@ -636,6 +784,9 @@ type Rules struct {
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158               bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsBerlin, IsLondon, IsCatalyst                          bool
+
+	CustomPrecompiles []common.Address     // Active custom precompiled contracts
+	OpcodeGas         map[string]OpcodeGas // Active opcode gas overrides
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -657,5 +808,8 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsBerlin:         c.IsBerlin(num),
 		IsLondon:         c.IsLondon(num),
 		IsCatalyst:       c.IsCatalyst(num),
+
+		CustomPrecompiles: c.customPrecompiles(num),
+		OpcodeGas:         c.opcodeGas(num),
 	}
 }
@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -87,6 +89,51 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     30,
 			},
 		},
+		{
+			stored:  &ChainConfig{},
+			new:     &ChainConfig{Precompiles: map[common.Address]*CustomPrecompile{{0x01}: {Name: "a", Block: big.NewInt(30)}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{Precompiles: map[common.Address]*CustomPrecompile{{0x01}: {Name: "a", Block: big.NewInt(10)}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0100000000000000000000000000000000000000 activation block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Precompiles: map[common.Address]*CustomPrecompile{{0x01}: {Name: "a", Block: big.NewInt(10)}}},
+			new:    &ChainConfig{Precompiles: map[common.Address]*CustomPrecompile{{0x01}: {Name: "b", Block: big.NewInt(10)}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0100000000000000000000000000000000000000 implementation",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{OpcodeGas: map[string]*OpcodeGas{"SLOAD": {Gas: 1000, Block: big.NewInt(30)}}},
+			new:     &ChainConfig{OpcodeGas: map[string]*OpcodeGas{"SLOAD": {Gas: 2000, Block: big.NewInt(40)}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{OpcodeGas: map[string]*OpcodeGas{"SLOAD": {Gas: 1000, Block: big.NewInt(10)}}},
+			new:    &ChainConfig{OpcodeGas: map[string]*OpcodeGas{"SLOAD": {Gas: 2000, Block: big.NewInt(10)}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "SLOAD gas override cost",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
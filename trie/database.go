@@ -56,6 +56,11 @@ var (
 	memcacheCommitTimeTimer  = metrics.NewRegisteredResettingTimer("trie/memcache/commit/time", nil)
 	memcacheCommitNodesMeter = metrics.NewRegisteredMeter("trie/memcache/commit/nodes", nil)
 	memcacheCommitSizeMeter  = metrics.NewRegisteredMeter("trie/memcache/commit/size", nil)
+
+	memcacheCleanSizeGauge     = metrics.NewRegisteredGauge("trie/memcache/clean/size", nil)
+	memcacheDirtySizeGauge     = metrics.NewRegisteredGauge("trie/memcache/dirty/size", nil)
+	memcacheDirtyNodesGauge    = metrics.NewRegisteredGauge("trie/memcache/dirty/nodes", nil)
+	memcachePreimagesSizeGauge = metrics.NewRegisteredGauge("trie/memcache/preimages/size", nil)
 )
 
 // Database is an intermediate write layer between the trie data structures and
@@ -365,6 +370,7 @@ func (db *Database) insertPreimage(hash common.Hash, preimage []byte) {
 	}
 	db.preimages[hash] = preimage
 	db.preimagesSize += common.StorageSize(common.HashLength + len(preimage))
+	memcachePreimagesSizeGauge.Update(int64(db.preimagesSize))
 }
 
 // node retrieves a cached trie node from memory, or returns nil if none can be
@@ -529,6 +535,7 @@ func (db *Database) Dereference(root common.Hash) {
 	memcacheGCTimeTimer.Update(time.Since(start))
 	memcacheGCSizeMeter.Mark(int64(storage - db.dirtiesSize))
 	memcacheGCNodesMeter.Mark(int64(nodes - len(db.dirties)))
+	db.updateSizeGauges()
 
 	log.Debug("Dereferenced trie from memory database", "nodes", nodes-len(db.dirties), "size", storage-db.dirtiesSize, "time", time.Since(start),
 		"gcnodes", db.gcnodes, "gcsize", db.gcsize, "gctime", db.gctime, "livenodes", len(db.dirties), "livesize", db.dirtiesSize)
@@ -584,6 +591,22 @@ func (db *Database) dereference(child common.Hash, parent common.Hash) {
 	}
 }
 
+// updateSizeGauges reports the current memory usage of the node caches and the
+// preimage store to the metrics system. The caller must hold the lock.
+func (db *Database) updateSizeGauges() {
+	if !metrics.Enabled {
+		return
+	}
+	if db.cleans != nil {
+		var stats fastcache.Stats
+		db.cleans.UpdateStats(&stats)
+		memcacheCleanSizeGauge.Update(int64(stats.BytesSize))
+	}
+	memcacheDirtySizeGauge.Update(int64(db.dirtiesSize + db.childrenSize))
+	memcacheDirtyNodesGauge.Update(int64(len(db.dirties)))
+	memcachePreimagesSizeGauge.Update(int64(db.preimagesSize))
+}
+
 // Cap iteratively flushes old but still referenced trie nodes until the total
 // memory usage goes below the given threshold.
 //
@@ -679,6 +702,7 @@ func (db *Database) Cap(limit common.StorageSize) error {
 	memcacheFlushTimeTimer.Update(time.Since(start))
 	memcacheFlushSizeMeter.Mark(int64(storage - db.dirtiesSize))
 	memcacheFlushNodesMeter.Mark(int64(nodes - len(db.dirties)))
+	db.updateSizeGauges()
 
 	log.Debug("Persisted nodes from memory database", "nodes", nodes-len(db.dirties), "size", storage-db.dirtiesSize, "time", time.Since(start),
 		"flushnodes", db.flushnodes, "flushsize", db.flushsize, "flushtime", db.flushtime, "livenodes", len(db.dirties), "livesize", db.dirtiesSize)
@@ -737,6 +761,7 @@ func (db *Database) Commit(node common.Hash, report bool, callback func(common.H
 	memcacheCommitTimeTimer.Update(time.Since(start))
 	memcacheCommitSizeMeter.Mark(int64(storage - db.dirtiesSize))
 	memcacheCommitNodesMeter.Mark(int64(nodes - len(db.dirties)))
+	db.updateSizeGauges()
 
 	logger := log.Info
 	if !report {
@@ -21,6 +21,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 // Tests that the trie database returns a missing trie node error if attempting
@@ -31,3 +32,50 @@ func TestDatabaseMetarootFetch(t *testing.T) {
 		t.Fatalf("metaroot retrieval succeeded")
 	}
 }
+
+// Tests that the size gauges follow the contents of the trie database, with the
+// preimage size reported as preimages are inserted, not only when flushing.
+func TestDatabaseSizeGauges(t *testing.T) {
+	// Swap in forcibly enabled gauges
+	defer func(enabled bool, dirtySize, dirtyNodes, preimagesSize metrics.Gauge) {
+		metrics.Enabled = enabled
+		memcacheDirtySizeGauge, memcacheDirtyNodesGauge, memcachePreimagesSizeGauge = dirtySize, dirtyNodes, preimagesSize
+	}(metrics.Enabled, memcacheDirtySizeGauge, memcacheDirtyNodesGauge, memcachePreimagesSizeGauge)
+	metrics.Enabled = true
+	memcacheDirtySizeGauge, memcacheDirtyNodesGauge, memcachePreimagesSizeGauge = metrics.NewGauge(), metrics.NewGauge(), metrics.NewGauge()
+
+	db := NewDatabaseWithConfig(memorydb.New(), &Config{Preimages: true})
+	trie, _ := NewSecure(common.Hash{}, db)
+	for i := byte(0); i < 16; i++ {
+		trie.Update([]byte{i}, common.LeftPadBytes([]byte{i + 1}, 32))
+	}
+	root, err := trie.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit trie: %v", err)
+	}
+	_, preimages := db.Size()
+	if have, want := memcachePreimagesSizeGauge.Value(), int64(preimages); have == 0 || have != want {
+		t.Errorf("preimage size gauge mismatch after insertion: have %d, want %d", have, want)
+	}
+	// Capping above the current usage must report the dirty caches untouched
+	db.Cap(1024 * 1024)
+	if have := memcacheDirtyNodesGauge.Value(); have == 0 {
+		t.Errorf("dirty node gauge not updated")
+	}
+	if have := memcacheDirtySizeGauge.Value(); have == 0 {
+		t.Errorf("dirty size gauge not updated")
+	}
+	// Committing everything must reset all the gauges, leaving only the meta root
+	if err := db.Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to commit database: %v", err)
+	}
+	if have := memcacheDirtyNodesGauge.Value(); have != 1 {
+		t.Errorf("dirty node gauge not reset: have %d, want %d", have, 1)
+	}
+	if have := memcacheDirtySizeGauge.Value(); have != 0 {
+		t.Errorf("dirty size gauge not reset: have %d", have)
+	}
+	if have := memcachePreimagesSizeGauge.Value(); have != 0 {
+		t.Errorf("preimage size gauge not reset: have %d", have)
+	}
+}
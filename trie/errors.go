@@ -33,3 +33,23 @@ type MissingNodeError struct {
 func (err *MissingNodeError) Error() string {
 	return fmt.Sprintf("missing trie node %x (path %x)", err.NodeHash, err.Path)
 }
+
+// ProofError is returned by the proof verification functions if the proof itself
+// is invalid, as opposed to proving that the key is absent from the trie. Either
+// a node on the path is missing from the proof, or it cannot be decoded.
+type ProofError struct {
+	Index int         // position of the offending node along the proof path
+	Hash  common.Hash // hash of the offending node
+	Err   error       // decoding failure, nil if the node is missing from the proof
+}
+
+func (err *ProofError) Error() string {
+	if err.Err == nil {
+		return fmt.Sprintf("proof node %d (hash %064x) missing", err.Index, err.Hash)
+	}
+	return fmt.Sprintf("bad proof node %d: %v", err.Index, err.Err)
+}
+
+func (err *ProofError) Unwrap() error {
+	return err.Err
+}
@@ -101,19 +101,19 @@ func (t *SecureTrie) Prove(key []byte, fromLevel uint, proofDb ethdb.KeyValueWri
 }
 
 // VerifyProof checks merkle proofs. The given proof must contain the value for
-// key in a trie with the given root hash. VerifyProof returns an error if the
-// proof contains invalid trie nodes or the wrong value.
+// key in a trie with the given root hash. VerifyProof returns a *ProofError if
+// the proof contains invalid trie nodes or the wrong value.
 func VerifyProof(rootHash common.Hash, key []byte, proofDb ethdb.KeyValueReader) (value []byte, err error) {
 	key = keybytesToHex(key)
 	wantHash := rootHash
 	for i := 0; ; i++ {
 		buf, _ := proofDb.Get(wantHash[:])
 		if buf == nil {
-			return nil, fmt.Errorf("proof node %d (hash %064x) missing", i, wantHash)
+			return nil, &ProofError{Index: i, Hash: wantHash}
 		}
 		n, err := decodeNode(wantHash[:], buf)
 		if err != nil {
-			return nil, fmt.Errorf("bad proof node %d: %v", i, err)
+			return nil, &ProofError{Index: i, Hash: wantHash, Err: err}
 		}
 		keyrest, cld := get(n, key, true)
 		switch cld := cld.(type) {
@@ -129,6 +129,17 @@ func VerifyProof(rootHash common.Hash, key []byte, proofDb ethdb.KeyValueReader)
 	}
 }
 
+// VerifyProofValue checks a merkle proof the same way as VerifyProof, returning
+// the proven value along with whether the key exists in the trie at all. Failed
+// verifications are reported as *ProofError, so they can be told apart from a
+// valid proof of the key's absence.
+func VerifyProofValue(rootHash common.Hash, key []byte, proofDb ethdb.KeyValueReader) (value []byte, exists bool, err error) {
+	if value, err = VerifyProof(rootHash, key, proofDb); err != nil {
+		return nil, false, err
+	}
+	return value, value != nil, nil
+}
+
 // proofToPath converts a merkle proof to trie node path. The main purpose of
 // this function is recovering a node path from the merkle proof stream. All
 // necessary nodes will be resolved and leave the remaining as hashnode.
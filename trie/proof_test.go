@@ -20,6 +20,7 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"encoding/binary"
+	"errors"
 	mrand "math/rand"
 	"sort"
 	"testing"
@@ -150,6 +151,40 @@ func TestMissingKeyProof(t *testing.T) {
 	}
 }
 
+// Tests that the proven value and the existence of the key are reported, and
+// that invalid proofs are told apart from proofs of absence.
+func TestVerifyProofValue(t *testing.T) {
+	trie := new(Trie)
+	updateString(trie, "k", "v")
+	root := trie.Hash()
+
+	for _, tt := range []struct {
+		key    string
+		exists bool
+	}{{"k", true}, {"a", false}} {
+		proof := memorydb.New()
+		trie.Prove([]byte(tt.key), 0, proof)
+
+		val, exists, err := VerifyProofValue(root, []byte(tt.key), proof)
+		if err != nil {
+			t.Fatalf("key %q: failed to verify proof: %v", tt.key, err)
+		}
+		if exists != tt.exists || (exists && string(val) != "v") {
+			t.Errorf("key %q: result mismatch: have %x/%v, want exists %v", tt.key, val, exists, tt.exists)
+		}
+	}
+	// Proofs lacking a node or containing garbage should be rejected
+	var perr *ProofError
+	if _, _, err := VerifyProofValue(root, []byte("k"), memorydb.New()); !errors.As(err, &perr) || perr.Err != nil {
+		t.Errorf("missing proof node error mismatch: have %v", err)
+	}
+	proof := memorydb.New()
+	proof.Put(root[:], []byte{0x01, 0x02})
+	if _, _, err := VerifyProofValue(root, []byte("k"), proof); !errors.As(err, &perr) || perr.Err == nil {
+		t.Errorf("bad proof node error mismatch: have %v", err)
+	}
+}
+
 type entrySlice []*kv
 
 func (p entrySlice) Len() int           { return len(p) }